@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+	"os/signal"
+	"time"
+
+	"go-pira/pkg/alert"
+	"go-pira/pkg/pira"
+)
+
+// runAlert monitors the analyzer and prints alert transitions as NDJSON
+func runAlert(args []string) error {
+	var device deviceFlags
+	fs := flag.NewFlagSet("alert", flag.ContinueOnError)
+	device.register(fs)
+	rulesPath := fs.String("rules", "", "JSON alert rules file")
+	interval := fs.Duration("interval", time.Second, "poll interval")
+	fast := fs.Duration("fast", 0, "deviation poll interval, 0 disables")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *rulesPath == "" {
+		return usagef("missing -rules")
+	}
+	rules, err := alert.LoadRules(*rulesPath)
+	if err != nil {
+		return err
+	}
+	engine, err := alert.NewEngine(rules, alert.NewJSONSink(os.Stdout), alert.LogSink{})
+	if err != nil {
+		return err
+	}
+
+	client, err := device.dial()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	monitor := pira.NewMonitor(client, pira.MonitorConfig{SlowInterval: *interval, FastInterval: *fast})
+	snapshots := monitor.Snapshots(ctx)
+	go monitor.Run(ctx)
+	for s := range snapshots {
+		if s.Err != nil {
+			slog.Warn("failed to read fm info", "error", s.Err)
+			continue
+		}
+		if _, err := engine.Evaluate(s.Time, alert.Vars(s.FMInfo, s.BasicData)); err != nil {
+			slog.Warn("failed to send alerts", "error", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"go-pira/pkg/pira"
+)
+
+// deviceFlags are the serial connection flags shared by the commands
+// that talk to the analyzer
+type deviceFlags struct {
+	port     string
+	baud     int
+	timeout  time.Duration
+	region   string
+	stations string
+}
+
+// globals are the device flags given before the command, they are the
+// defaults of the command flags
+var globals = deviceFlags{
+	port:    "/dev/tty.usbserial-A8ATQQ5Y",
+	baud:    115_200,
+	timeout: 500 * time.Millisecond,
+	region:  "rds",
+}
+
+// fromEnv overrides the defaults with the GPIRA_* environment variables
+func (d *deviceFlags) fromEnv() error {
+	if v := os.Getenv("GPIRA_PORT"); v != "" {
+		d.port = v
+	}
+	if v := os.Getenv("GPIRA_BAUD"); v != "" {
+		baud, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid GPIRA_BAUD %q", v)
+		}
+		d.baud = baud
+	}
+	if v := os.Getenv("GPIRA_TIMEOUT"); v != "" {
+		timeout, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("invalid GPIRA_TIMEOUT %q", v)
+		}
+		d.timeout = timeout
+	}
+	if v := os.Getenv("GPIRA_REGION"); v != "" {
+		d.region = v
+	}
+	if v := os.Getenv("GPIRA_STATIONS"); v != "" {
+		d.stations = v
+	}
+	return nil
+}
+
+func (d *deviceFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&d.port, "port", globals.port, "serial port ($GPIRA_PORT)")
+	fs.IntVar(&d.baud, "baud", globals.baud, "baud rate ($GPIRA_BAUD)")
+	fs.DurationVar(&d.timeout, "timeout", globals.timeout, "read timeout ($GPIRA_TIMEOUT)")
+	fs.StringVar(&d.region, "region", globals.region, "programme type region: rds or rbds ($GPIRA_REGION)")
+	fs.StringVar(&d.stations, "stations", globals.stations, "station database naming EON linked services, JSON array of pi, name, ps ($GPIRA_STATIONS)")
+}
+
+func (d *deviceFlags) dial() (*pira.Pira, error) {
+	region, err := pira.ParseRegion(d.region)
+	if err != nil {
+		return nil, &usageError{err}
+	}
+	var stations pira.StationDB
+	if d.stations != "" {
+		if stations, err = pira.LoadStationDB(d.stations); err != nil {
+			return nil, err
+		}
+	}
+	client, err := pira.Dial(d.port, d.baud, d.timeout)
+	if err != nil {
+		return nil, &deviceError{err}
+	}
+	client.SetRegion(region)
+	client.SetStationDB(stations)
+	return client, nil
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"flag"
+
+	"go-pira/pkg/pira"
+)
+
+// runEON prints the services linked through EON, named from the -stations
+// database
+func runEON(args []string) error {
+	var device deviceFlags
+	var output outputFlags
+	fs := flag.NewFlagSet("eon", flag.ContinueOnError)
+	device.register(fs)
+	output.register(fs, "")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	r, err := output.renderer()
+	if err != nil {
+		return err
+	}
+
+	client, err := device.dial()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	var fmi pira.FMInfo
+	if err := client.GetFMInfo(&fmi); err != nil {
+		return err
+	}
+	return r.Render(fmi.LinkedServices)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"go-pira/pkg/metrics"
+	"go-pira/pkg/pira"
+)
+
+// runExporter serves the analyzer readings as Prometheus metrics
+func runExporter(args []string) error {
+	var device deviceFlags
+	fs := flag.NewFlagSet("exporter", flag.ContinueOnError)
+	device.register(fs)
+	listen := fs.String("listen", ":9567", "HTTP listen address")
+	name := fs.String("device", "", "device label, defaults to the serial port")
+	station := fs.String("station", "", "station label, defaults to the RDS PI code")
+	interval := fs.Duration("interval", 5*time.Second, "poll interval")
+	fast := fs.Duration("fast", 0, "deviation poll interval, 0 disables")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *name == "" {
+		*name = device.port
+	}
+
+	client, err := device.dial()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	source := metrics.NewInstrumentedSource(client, metrics.Label{Name: "device", Value: *name})
+	source.Station = *station
+	collector := &metrics.FMInfoCollector{Device: *name, Station: *station}
+	monitor := pira.NewMonitor(source, pira.MonitorConfig{SlowInterval: *interval, FastInterval: *fast})
+	snapshots := monitor.Snapshots(ctx)
+	go func() {
+		for s := range snapshots {
+			if s.Err != nil {
+				slog.Warn("failed to read fm info", "error", s.Err)
+			}
+			collector.Update(s)
+		}
+	}()
+	go monitor.Run(ctx)
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", metrics.Handler(collector, source))
+	server := &http.Server{Addr: *listen, Handler: mux}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+	slog.Info("serving metrics", "address", *listen)
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -1,61 +1,216 @@
 package main
 
 import (
-	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"log/slog"
 	"os"
-	"time"
+	"runtime"
+	"slices"
+	"strings"
 
-	"go-pira/pkg/pira"
+	"go.bug.st/serial"
 )
 
+// Version, BuildTime and GitCommit are set by the Makefile through LDFLAGS
+var (
+	Version   = "dev"
+	BuildTime = "unknown"
+	GitCommit = "unknown"
+)
+
+// Exit codes
+const (
+	exitOK     = 0
+	exitError  = 1
+	exitUsage  = 2
+	exitDevice = 3
+)
+
+// command is a subcommand selected by the first argument
+type command struct {
+	run     func(args []string) error
+	summary string
+}
+
+var commands = map[string]command{
+	"alert":      {runAlert, "evaluate alert rules against the readings"},
+	"basic":      {runBasic, "print the basic data"},
+	"dump":       {runDump, "dump analyzer memory"},
+	"eon":        {runEON, "print the services linked through EON"},
+	"exporter":   {runExporter, "serve Prometheus metrics"},
+	"get":        {runGet, "print a single field"},
+	"influx":     {runInflux, "write InfluxDB line protocol, live or from a recorded log"},
+	"info":       {runInfo, "print the FM and RDS readings"},
+	"mqtt":       {runMQTT, "publish readings and events over MQTT"},
+	"nowplaying": {runNowPlaying, "log the RadioText Plus now playing history"},
+	"ps":         {runPS, "detect and reconstruct dynamic PS"},
+	"raw":        {runRaw, "send a raw command and print the response"},
+	"rds":        {runRDS, "print the RDS readings or decode a group log"},
+	"record":     {runRecord, "log snapshots to rotating NDJSON or CSV files"},
+	"serve":      {runServe, "serve the HTTP JSON API"},
+	"version":    {runVersion, "print the version"},
+	"watch":      {runWatch, "print snapshots or change events as they happen"},
+}
+
+// usageError marks errors in the command line
+type usageError struct {
+	err error
+}
+
+func (e *usageError) Error() string { return e.err.Error() }
+func (e *usageError) Unwrap() error { return e.err }
+
+// usagef returns a usage error
+func usagef(format string, args ...any) error {
+	return &usageError{fmt.Errorf(format, args...)}
+}
+
+// levelFlag sets the level of the default logger
+type levelFlag struct {
+	level slog.Level
+}
+
+func (l *levelFlag) String() string {
+	return strings.ToLower(l.level.String())
+}
+
+func (l *levelFlag) Set(s string) error {
+	if err := l.level.UnmarshalText([]byte(s)); err != nil {
+		return fmt.Errorf("invalid log level %s", s)
+	}
+	slog.SetLogLoggerLevel(l.level)
+	return nil
+}
+
+// logLevel is set by --log-level before or after the command
+var logLevel levelFlag
+
+const logLevelUsage = "log level: debug, info, warn or error ($GPIRA_LOG_LEVEL)"
+
+// parseFlags adds --log-level and parses the command flags, errors are
+// usage errors
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	fs.Var(&logLevel, "log-level", logLevelUsage)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return &usageError{err}
+	}
+	return nil
+}
+
+// deviceError marks failures to open or talk to the analyzer
+type deviceError struct {
+	err error
+}
+
+func (e *deviceError) Error() string { return e.err.Error() }
+func (e *deviceError) Unwrap() error { return e.err }
+
+// exitCode maps the error of a command to the process exit code
+func exitCode(err error) int {
+	var usageErr *usageError
+	var deviceErr *deviceError
+	var portErr *serial.PortError
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.As(err, &usageErr):
+		return exitUsage
+	case errors.As(err, &deviceErr), errors.As(err, &portErr):
+		return exitDevice
+	}
+	return exitError
+}
+
 func main() {
-	client, err := pira.Dial("/dev/tty.usbserial-A8ATQQ5Y", 115_200, 500*time.Millisecond)
-	if err != nil {
-		fmt.Println("Error dialing Pira:", err)
-		os.Exit(1)
+	os.Exit(run(os.Args[1:]))
+}
+
+// run parses the global flags and runs the command, it returns the
+// exit code
+func run(args []string) int {
+	if err := globals.fromEnv(); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return exitUsage
+	}
+	if err := logLevel.Set(envOr("GPIRA_LOG_LEVEL", "info")); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return exitUsage
+	}
+	fs := flag.NewFlagSet("gpira", flag.ContinueOnError)
+	globals.register(fs)
+	fs.Var(&logLevel, "log-level", logLevelUsage)
+	fs.StringVar(&globalFormat, "format", os.Getenv("GPIRA_FORMAT"), "output format of the commands printing results, see their -format")
+	fs.Usage = func() { usage(fs) }
+	if err := fs.Parse(args); err != nil {
+		return exitCode(&usageError{err})
 	}
-	defer client.Close()
 
-	slog.SetLogLoggerLevel(slog.LevelDebug)
-	basicData, err := client.GetBasicData()
-	if err != nil {
-		fmt.Println("Error getting basic data:", err)
-		os.Exit(1)
+	if fs.NArg() == 0 {
+		usage(fs)
+		return exitUsage
 	}
-	jsonData, err := json.Marshal(basicData)
-	if err != nil {
-		fmt.Println("Error marshalling basic data:", err)
-		os.Exit(1)
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		fmt.Fprintln(os.Stderr, "Unknown command:", fs.Arg(0))
+		return exitUsage
+	}
+	err := cmd.run(fs.Args()[1:])
+	code := exitCode(err)
+	if code != exitOK {
+		fmt.Fprintln(os.Stderr, "Error:", err)
 	}
-	fmt.Println("Basic data:", string(jsonData))
+	return code
+}
 
-	memory := pira.MemoryPart1{}
-	err = client.Load(0x1A, &memory)
-	if err != nil {
-		fmt.Println("Error getting memory:", err)
-		os.Exit(1)
+func usage(fs *flag.FlagSet) {
+	w := fs.Output()
+	fmt.Fprintln(w, "Usage: gpira [flags] <command> [command flags] [arguments]")
+	fmt.Fprintln(w, "\nCommands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
 	}
-	jsonData, err = json.Marshal(memory)
-	if err != nil {
-		fmt.Println("Error marshalling memory:", err)
-		os.Exit(1)
+	slices.Sort(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-12s %s\n", name, commands[name].summary)
 	}
-	fmt.Println("Memory:", string(jsonData))
+	fmt.Fprintln(w, "\nFlags:")
+	fmt.Fprintln(w, "  --log-level is also accepted after any command, the device flags after")
+	fmt.Fprintln(w, "  the commands reading the analyzer and --format after those printing results")
+	fs.PrintDefaults()
+}
 
-	memory2 := pira.MemoryPart2{}
-	err = client.Load(0x48C, &memory2)
-	if err != nil {
-		fmt.Println("Error getting memory2:", err)
-		os.Exit(1)
+// versionInfo is the build information printed by the version command
+type versionInfo struct {
+	Version   string `json:"version"`
+	GitCommit string `json:"git_commit"`
+	BuildTime string `json:"build_time"`
+	Go        string `json:"go"`
+	Platform  string `json:"platform"`
+}
+
+// runVersion prints the build information
+func runVersion(args []string) error {
+	var output outputFlags
+	fs := flag.NewFlagSet("version", flag.ContinueOnError)
+	output.register(fs, "gpira {{.Version}} (commit {{.GitCommit}}, built {{.BuildTime}}, {{.Go}} {{.Platform}})")
+	if err := parseFlags(fs, args); err != nil {
+		return err
 	}
-	jsonData, err = json.Marshal(memory2)
+	r, err := output.renderer()
 	if err != nil {
-		fmt.Println("Error marshalling memory2:", err)
-		os.Exit(1)
+		return err
 	}
-	fmt.Println("Memory2:", string(jsonData))
-
-	os.Exit(0)
+	return r.Render(versionInfo{
+		Version:   Version,
+		GitCommit: GitCommit,
+		BuildTime: BuildTime,
+		Go:        runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	})
 }
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go-pira/pkg/pira"
+	"go-pira/pkg/record"
+	"go-pira/pkg/render"
+)
+
+func TestRun_ExitCodes(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"no command", nil, exitUsage},
+		{"unknown command", []string{"nope"}, exitUsage},
+		{"unknown flag", []string{"--bogus", "version"}, exitUsage},
+		{"invalid log level", []string{"--log-level", "loud", "version"}, exitUsage},
+		{"log level after the command", []string{"version", "--log-level", "debug"}, exitOK},
+		{"invalid log level after the command", []string{"version", "--log-level", "loud"}, exitUsage},
+		{"missing argument", []string{"get"}, exitUsage},
+		{"missing required flag", []string{"alert"}, exitUsage},
+		{"invalid format", []string{"--format", "xml", "version"}, exitUsage},
+		{"help", []string{"version", "-h"}, exitOK},
+		{"version", []string{"version"}, exitOK},
+		{"device", []string{"--port", "/nonexistent/tty", "info"}, exitDevice},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := run(tt.args); got != tt.want {
+				t.Errorf("run(%q) = %d, want %d", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRun_InfluxBackfill(t *testing.T) {
+	dir := t.TempDir()
+	logPath, out := filepath.Join(dir, "survey.ndjson"), filepath.Join(dir, "points.lp")
+	f, err := os.Create(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	w := record.NewWriter(f, record.FormatNDJSON)
+	w.Write(pira.Snapshot{Seq: 1, Time: at, FMInfo: &pira.FMInfo{Frequency: 98500, DeviationMax: 75000}})
+	f.Close()
+
+	if got := run([]string{"influx", "-from", logPath, "-target", out, "-device", "pira1"}); got != exitOK {
+		t.Fatalf("run() = %d, want %d", got, exitOK)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "pira_deviation,") || !strings.Contains(string(data), " 1735732800000000000\n") {
+		t.Errorf("points = %s, want the recorded time", data)
+	}
+}
+
+func TestSkipLine_ResumedCSV(t *testing.T) {
+	var buf bytes.Buffer
+	r, err := render.New(&skipLine{w: &buf}, "csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	r.Render(pira.NowPlayingEntry{Time: at, Artist: "A", Title: "T", RT: "A - T"})
+	r.Render(pira.NowPlayingEntry{Time: at, Artist: "B", Title: "U", Album: "L", RT: "B - U"})
+	want := "2025-01-01T12:00:00Z,A,T,,A - T\n2025-01-01T12:00:00Z,B,U,L,B - U\n"
+	if buf.String() != want {
+		t.Errorf("history = %q, want %q", buf.String(), want)
+	}
+}
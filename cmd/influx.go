@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"time"
+
+	"go-pira/pkg/influx"
+	"go-pira/pkg/pira"
+	"go-pira/pkg/record"
+)
+
+// backfillBatch is the number of points written at once by -from
+const backfillBatch = 5000
+
+// runInflux writes the analyzer readings as InfluxDB line protocol, or
+// the snapshots of a recorded log with -from
+func runInflux(args []string) error {
+	var device deviceFlags
+	fs := flag.NewFlagSet("influx", flag.ContinueOnError)
+	device.register(fs)
+	target := fs.String("target", "-", "destination: - for stdout, a file, udp://host:port or an HTTP write URL")
+	token := fs.String("token", os.Getenv("GPIRA_INFLUX_TOKEN"), "HTTP API token, defaults to $GPIRA_INFLUX_TOKEN")
+	name := fs.String("device", "", "device tag, defaults to the serial port")
+	station := fs.String("station", "", "station tag")
+	interval := fs.Duration("interval", 5*time.Second, "poll interval")
+	fast := fs.Duration("fast", 0, "deviation poll interval, 0 disables")
+	basic := fs.Bool("basic", false, "also read the basic data")
+	from := fs.String("from", "", "backfill from an NDJSON snapshot log of gpira record, gzip allowed, instead of reading the analyzer")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *name == "" {
+		*name = device.port
+	}
+
+	writer, err := influx.Open(*target, *token)
+	if err != nil {
+		return err
+	}
+	defer writer.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var tags []influx.Tag
+	if *station != "" {
+		tags = append(tags, influx.Tag{Key: "station", Value: *station})
+	}
+	if *from != "" {
+		return backfillInflux(ctx, writer, *from, *name, tags)
+	}
+
+	client, err := device.dial()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	monitor := pira.NewMonitor(client, pira.MonitorConfig{SlowInterval: *interval, FastInterval: *fast, BasicData: *basic})
+	snapshots := monitor.Snapshots(ctx)
+	go monitor.Run(ctx)
+	for s := range snapshots {
+		if s.Err != nil {
+			slog.Warn("failed to read fm info", "error", s.Err)
+			continue
+		}
+		if s.BasicErr != nil {
+			slog.Warn("failed to read basic data", "error", s.BasicErr)
+		}
+		if err := writer.WritePoints(ctx, influx.Points(s, *name, tags...)); err != nil {
+			slog.Warn("failed to write points", "error", err)
+		}
+	}
+	return nil
+}
+
+// backfillInflux writes the snapshots of a recorded log with their
+// original timestamps
+func backfillInflux(ctx context.Context, writer influx.Writer, path, device string, tags []influx.Tag) error {
+	log, err := record.OpenLog(path)
+	if err != nil {
+		return err
+	}
+	defer log.Close()
+
+	r := record.NewReader(log)
+	var points []influx.Point
+	for ctx.Err() == nil {
+		s, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		points = append(points, influx.Points(s, device, tags...)...)
+		if len(points) >= backfillBatch {
+			if err := writer.WritePoints(ctx, points); err != nil {
+				return err
+			}
+			points = points[:0]
+		}
+	}
+	if len(points) == 0 {
+		return ctx.Err()
+	}
+	return writer.WritePoints(ctx, points)
+}
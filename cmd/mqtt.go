@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"go-pira/pkg/alert"
+	"go-pira/pkg/mqtt"
+	"go-pira/pkg/pira"
+)
+
+// runMQTT publishes readings, change events and alerts to an MQTT broker
+func runMQTT(args []string) error {
+	var device deviceFlags
+	fs := flag.NewFlagSet("mqtt", flag.ContinueOnError)
+	device.register(fs)
+	broker := fs.String("broker", "localhost:1883", "broker address")
+	name := fs.String("device", "", "device name in the topics, defaults to the serial port name")
+	username := fs.String("username", "", "broker user name")
+	password := fs.String("password", os.Getenv("GPIRA_MQTT_PASSWORD"), "broker password, defaults to $GPIRA_MQTT_PASSWORD")
+	qos := fs.Uint("qos", 0, "publish QoS, 0 or 1")
+	interval := fs.Duration("interval", time.Second, "poll interval")
+	rulesPath := fs.String("rules", "", "JSON alert rules file")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *qos > 1 {
+		return usagef("unsupported qos %d", *qos)
+	}
+	if *name == "" {
+		*name = filepath.Base(device.port)
+	}
+
+	opts := mqtt.Options{
+		Broker:   *broker,
+		Username: *username,
+		Password: *password,
+	}
+	if err := opts.Validate(); err != nil {
+		return &usageError{err}
+	}
+	publisher := mqtt.NewPublisher(opts, "pira/"+*name, byte(*qos))
+
+	var engine *alert.Engine
+	if *rulesPath != "" {
+		rules, err := alert.LoadRules(*rulesPath)
+		if err != nil {
+			return err
+		}
+		// the log sink comes first so alerts are logged during broker outages
+		if engine, err = alert.NewEngine(rules, alert.LogSink{}, publisher); err != nil {
+			return err
+		}
+	}
+
+	client, err := device.dial()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// the client publishes the offline status when ctx is done, wait for
+	// it before exiting
+	clientDone := make(chan struct{})
+	go func() {
+		defer close(clientDone)
+		publisher.Client().Run(ctx)
+	}()
+	defer func() {
+		stop()
+		<-clientDone
+	}()
+	monitor := pira.NewMonitor(client, pira.MonitorConfig{SlowInterval: *interval})
+	snapshots := monitor.Snapshots(ctx)
+	go monitor.Run(ctx)
+
+	differ := pira.NewDiffer(pira.DifferConfig{})
+	for s := range snapshots {
+		if s.Err != nil {
+			slog.Warn("failed to read fm info", "error", s.Err)
+		}
+		if engine != nil && s.Err == nil {
+			if _, err := engine.Evaluate(s.Time, alert.Vars(s.FMInfo, s.BasicData)); err != nil {
+				slog.Warn("failed to evaluate alerts", "error", err)
+			}
+		}
+		events := differ.Snapshot(s)
+		// readings are not queued while the broker is unreachable, the
+		// next snapshot after the reconnection refreshes the retained values
+		if !publisher.Client().Connected() {
+			slog.Debug("broker not connected, skipping snapshot", "seq", s.Seq, "events", len(events))
+			continue
+		}
+		publish(ctx, publisher, s, events)
+	}
+	return nil
+}
+
+// publish publishes a snapshot and its events, bounded by the publisher
+// timeout so a lost connection does not stall the poll loop
+func publish(ctx context.Context, publisher *mqtt.Publisher, s pira.Snapshot, events []pira.Event) {
+	ctx, cancel := context.WithTimeout(ctx, publisher.Timeout)
+	defer cancel()
+	if err := publisher.PublishSnapshot(ctx, s); err != nil {
+		slog.Warn("failed to publish snapshot", "error", err)
+	}
+	if err := publisher.PublishEvents(ctx, events); err != nil {
+		slog.Warn("failed to publish events", "error", err)
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"time"
+
+	"go-pira/pkg/pira"
+)
+
+// runNowPlaying polls RT and RT+ and writes the now playing history until interrupted
+func runNowPlaying(args []string) error {
+	var device deviceFlags
+	var output outputFlags
+	fs := flag.NewFlagSet("nowplaying", flag.ContinueOnError)
+	device.register(fs)
+	output.register(fs, "ndjson")
+	interval := fs.Duration("interval", 2*time.Second, "poll interval")
+	file := fs.String("output", "", "append history to file instead of stdout")
+	fmInfo := fs.Bool("fminfo", false, "read the full FM info instead of RT and RT+ only")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	var (
+		out     io.Writer = os.Stdout
+		resumed bool
+	)
+	if *file != "" {
+		f, err := os.OpenFile(*file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if info, err := f.Stat(); err == nil && info.Size() > 0 {
+			resumed = true
+		}
+		out = f
+	}
+	if resumed && output.format == "csv" {
+		out = &skipLine{w: out}
+	}
+	r, err := output.rendererTo(out)
+	if err != nil {
+		return err
+	}
+
+	client, err := device.dial()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	tracker := pira.NewNowPlayingTracker()
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	for {
+		rt, rtPlus, err := readNowPlaying(client, *fmInfo)
+		if err != nil {
+			slog.Warn("failed to read radio text", "error", err)
+		} else if entry, ok := tracker.Update(time.Now(), rt, rtPlus); ok {
+			if err := r.Render(entry); err != nil {
+				return err
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func readNowPlaying(client *pira.Pira, fmInfo bool) (string, pira.RTPlus, error) {
+	if fmInfo {
+		var fmi pira.FMInfo
+		if err := client.GetFMInfo(&fmi); err != nil {
+			return "", pira.RTPlus{}, err
+		}
+		return fmi.RDS.RT, fmi.RDS.RTPlus, nil
+	}
+	rt, err := client.GetRDSRT()
+	if err != nil {
+		return "", pira.RTPlus{}, err
+	}
+	rtPlus, err := client.GetRDSRTPlus()
+	if err != nil {
+		return "", pira.RTPlus{}, err
+	}
+	return rt, *rtPlus, nil
+}
+
+// skipLine drops the first line written through it, the CSV header when
+// appending to an existing history
+type skipLine struct {
+	w       io.Writer
+	skipped bool
+}
+
+func (s *skipLine) Write(p []byte) (int, error) {
+	n := len(p)
+	if !s.skipped {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			return n, nil
+		}
+		s.skipped = true
+		p = p[i+1:]
+	}
+	if _, err := s.w.Write(p); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+
+	"go-pira/pkg/render"
+)
+
+// globalFormat is the --format given before the command, the default of
+// the command -format flags
+var globalFormat string
+
+// outputFlags select the renderer of the commands printing results
+type outputFlags struct {
+	format string
+}
+
+// register adds -format, fallback applies when no --format was given
+// before the command. An empty format is a table on a terminal and JSON
+// otherwise.
+func (o *outputFlags) register(fs *flag.FlagSet, fallback string) {
+	format := globalFormat
+	if format == "" {
+		format = fallback
+	}
+	fs.StringVar(&o.format, "format", format, "output format: table, json, ndjson, yaml, csv or a Go template such as '{{.RDS.PS}}' ($GPIRA_FORMAT)")
+}
+
+func (o *outputFlags) renderer() (render.Renderer, error) {
+	return o.rendererTo(os.Stdout)
+}
+
+// rendererTo returns the renderer writing to w, the empty format is a
+// table when w is a terminal
+func (o *outputFlags) rendererTo(w io.Writer) (render.Renderer, error) {
+	format := o.format
+	if format == "" {
+		format = "json"
+		if f, ok := w.(*os.File); ok && isTerminal(f) {
+			format = "table"
+		}
+	}
+	r, err := render.New(w, format)
+	if err != nil {
+		return nil, &usageError{err}
+	}
+	return r, nil
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"os"
+	"os/signal"
+	"time"
+
+	"go-pira/pkg/pira"
+)
+
+// errDynamicPS is returned by the ps command when --static is set and the
+// station scrolls text through PS
+var errDynamicPS = errors.New("dynamic PS violates the static PS policy")
+
+// runPS samples PS at a high rate, then prints the reconstructed message
+// sequence and cycle period
+func runPS(args []string) error {
+	var device deviceFlags
+	var output outputFlags
+	fs := flag.NewFlagSet("ps", flag.ContinueOnError)
+	device.register(fs)
+	output.register(fs, "")
+	interval := fs.Duration("interval", 100*time.Millisecond, "sample interval")
+	duration := fs.Duration("duration", time.Minute, "sampling duration")
+	static := fs.Bool("static", false, "fail when the PS is dynamic")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	r, err := output.renderer()
+	if err != nil {
+		return err
+	}
+
+	client, err := device.dial()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, *duration)
+	defer cancel()
+
+	tracker := pira.NewPSTracker()
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+sampling:
+	for {
+		ps, err := client.GetRDSPS()
+		if err != nil {
+			slog.Warn("failed to read ps", "error", err)
+		} else {
+			tracker.Add(time.Now(), ps)
+		}
+		select {
+		case <-ctx.Done():
+			break sampling
+		case <-ticker.C:
+		}
+	}
+
+	report := tracker.Report()
+	if err := r.Render(report); err != nil {
+		return err
+	}
+	if *static && report.Dynamic {
+		return errDynamicPS
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"go-pira/pkg/pira"
+	"go-pira/pkg/rds"
+)
+
+// runRDS prints the RDS readings, "rds decode" decodes a group log
+func runRDS(args []string) error {
+	if len(args) > 0 && args[0] == "decode" {
+		return runRDSDecode(args[1:])
+	}
+	return runRDSInfo(args)
+}
+
+// runRDSDecode decodes a hex or RDS Spy group log (the format is detected
+// per line) and prints the RDSInfo timeline, by default as one JSON object
+// per line
+func runRDSDecode(args []string) error {
+	var output outputFlags
+	fs := flag.NewFlagSet("rds decode", flag.ContinueOnError)
+	region := fs.String("region", globals.region, "programme type region: rds or rbds")
+	output.register(fs, "ndjson")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return usagef("usage: gpira rds decode [flags] <file>")
+	}
+	path := fs.Arg(0)
+
+	rg, err := pira.ParseRegion(*region)
+	if err != nil {
+		return &usageError{err}
+	}
+	r, err := output.renderer()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	for entry, err := range rds.Timeline(rds.NewReader(f), rds.NewDecoder(rg)) {
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if err := r.Render(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-pira/pkg/fields"
+	"go-pira/pkg/pira"
+)
+
+// readCommand parses the device and output flags of a command without
+// further flags, reads with read and prints the result
+func readCommand(name string, args []string, read func(p *pira.Pira) (any, error)) error {
+	var device deviceFlags
+	var output outputFlags
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	device.register(fs)
+	output.register(fs, "")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return usagef("usage: gpira %s [flags]", name)
+	}
+	r, err := output.renderer()
+	if err != nil {
+		return err
+	}
+	client, err := device.dial()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	v, err := read(client)
+	if err != nil {
+		return err
+	}
+	return r.Render(v)
+}
+
+// runInfo prints the FM and RDS readings
+func runInfo(args []string) error {
+	return readCommand("info", args, func(p *pira.Pira) (any, error) {
+		var fmi pira.FMInfo
+		err := p.GetFMInfo(&fmi)
+		return &fmi, err
+	})
+}
+
+// runBasic prints the basic data of the ? terminal command
+func runBasic(args []string) error {
+	return readCommand("basic", args, func(p *pira.Pira) (any, error) {
+		return p.GetBasicData()
+	})
+}
+
+// runRDSInfo prints the RDS readings
+func runRDSInfo(args []string) error {
+	return readCommand("rds", args, func(p *pira.Pira) (any, error) {
+		var fmi pira.FMInfo
+		err := p.GetFMInfo(&fmi)
+		return &fmi.RDS, err
+	})
+}
+
+// getter reads a single field
+type getter func(p *pira.Pira) (any, error)
+
+func value[T any](get func(p *pira.Pira) (T, error)) getter {
+	return func(p *pira.Pira) (any, error) { return get(p) }
+}
+
+func deviation(dt pira.DeviationType) getter {
+	return func(p *pira.Pira) (any, error) { return p.GetDeviation(dt) }
+}
+
+// getters read fields with a single memory read, keyed by FMInfo paths
+// normalized by fields.Normalize
+var getters = map[string]getter{
+	"frequency":          value((*pira.Pira).GetFrequency),
+	"pilotdeviation":     deviation(pira.DeviationPilot),
+	"rdsdeviation":       deviation(pira.DeviationRDS),
+	"rdsphasedifference": value((*pira.Pira).GetRDSPhaseDifference),
+	"deviationmax":       deviation(pira.DeviationMax),
+	"deviationaverage":   deviation(pira.DeviationAve),
+	"modulationpower":    value((*pira.Pira).GetModulationPower),
+	"deviationminhold":   deviation(pira.DeviationMinHold),
+	"deviationmaxhold":   deviation(pira.DeviationMaxHold),
+	"deviation":          deviation(pira.Deviation),
+	"signalquality":      value((*pira.Pira).GetSignalQuality),
+	"am":                 value((*pira.Pira).GetAM),
+	"noiselevel":         value((*pira.Pira).GetNoiseLevel),
+	"histogram":          value((*pira.Pira).GetHistogram),
+	"rds.pi":             value((*pira.Pira).GetRDSPI),
+	"rds.ps":             value((*pira.Pira).GetRDSPS),
+	"rds.pty":            value((*pira.Pira).GetRDSPTY),
+	"rds.status":         value((*pira.Pira).GetRDSStatus),
+	"rds.eonpi":          value((*pira.Pira).GetRDSEONPI),
+	"rds.rt":             value((*pira.Pira).GetRDSRT),
+	"rds.ptyn":           value((*pira.Pira).GetRDSPTYN),
+	"rds.ct":             value((*pira.Pira).GetRDSCT),
+	"rds.mjd":            value((*pira.Pira).GetRDSMJD),
+	"rds.rtplus":         value((*pira.Pira).GetRDSRTPlus),
+	"rds.pin":            value((*pira.Pira).GetRDSPIN),
+	"rds.lic":            value((*pira.Pira).GetRDSLIC),
+	"rds.ecc":            value((*pira.Pira).GetRDSECC),
+	"rds.longps":         value((*pira.Pira).GetRDSLongPS),
+	"rds.groups": func(p *pira.Pira) (any, error) {
+		return p.GetRDSGroupStats(nil)
+	},
+}
+
+// runGet prints a single field. Fields with a getter take one memory
+// read, any other FMInfo path reads the full FMInfo.
+func runGet(args []string) error {
+	var device deviceFlags
+	var output outputFlags
+	fs := flag.NewFlagSet("get", flag.ContinueOnError)
+	device.register(fs)
+	output.register(fs, "")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return usagef("usage: gpira get [flags] <field>, e.g. rds.ps or deviation_max")
+	}
+	path := fs.Arg(0)
+	r, err := output.renderer()
+	if err != nil {
+		return err
+	}
+
+	client, err := device.dial()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if get, ok := getters[fields.Normalize(path)]; ok {
+		v, err := get(client)
+		if err != nil {
+			return err
+		}
+		return r.Render(v)
+	}
+	var fmi pira.FMInfo
+	if err := client.GetFMInfo(&fmi); err != nil {
+		return err
+	}
+	data, err := json.Marshal(&fmi)
+	if err != nil {
+		return err
+	}
+	var object any
+	if err := json.Unmarshal(data, &object); err != nil {
+		return err
+	}
+	v, ok := fields.Lookup(object, path)
+	if !ok {
+		return usagef("unknown field %s", fs.Arg(0))
+	}
+	return r.Render(v)
+}
+
+// runWatch prints snapshots, or with -events the change events, until
+// interrupted, by default as one JSON object per line
+func runWatch(args []string) error {
+	var device deviceFlags
+	var output outputFlags
+	fs := flag.NewFlagSet("watch", flag.ContinueOnError)
+	device.register(fs)
+	output.register(fs, "ndjson")
+	interval := fs.Duration("interval", time.Second, "poll interval")
+	fast := fs.Duration("fast", 0, "deviation poll interval, 0 disables")
+	basic := fs.Bool("basic", false, "also read the basic data")
+	events := fs.Bool("events", false, "print change events instead of snapshots")
+	debounce := fs.Int("debounce", 2, "reads a change must persist for")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	r, err := output.renderer()
+	if err != nil {
+		return err
+	}
+
+	client, err := device.dial()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	differ := pira.NewDiffer(pira.DifferConfig{Debounce: *debounce})
+	monitor := pira.NewMonitor(client, pira.MonitorConfig{SlowInterval: *interval, FastInterval: *fast, BasicData: *basic})
+	snapshots := monitor.Snapshots(ctx)
+	go monitor.Run(ctx)
+	for s := range snapshots {
+		if !*events {
+			if err := r.Render(s); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, event := range differ.Snapshot(s) {
+			if err := r.Render(event); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// runDump prints a hex dump of an analyzer memory range, without -addr
+// the two known memory blocks are rendered in the output format
+func runDump(args []string) error {
+	var device deviceFlags
+	var output outputFlags
+	fs := flag.NewFlagSet("dump", flag.ContinueOnError)
+	device.register(fs)
+	output.register(fs, "")
+	addr := fs.String("addr", "", "start address, hexadecimal")
+	n := fs.Int("len", 256, "number of bytes")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	var start int64
+	if *addr != "" {
+		var err error
+		start, err = strconv.ParseInt(strings.TrimPrefix(strings.ToLower(*addr), "0x"), 16, 0)
+		if err != nil {
+			return usagef("invalid address %s", *addr)
+		}
+	}
+
+	client, err := device.dial()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if *addr == "" {
+		var memory struct {
+			Part1 pira.MemoryPart1 `json:"part1"`
+			Part2 pira.MemoryPart2 `json:"part2"`
+		}
+		if err := client.Load(0x1A, &memory.Part1); err != nil {
+			return err
+		}
+		if err := client.Load(0x48C, &memory.Part2); err != nil {
+			return err
+		}
+		r, err := output.renderer()
+		if err != nil {
+			return err
+		}
+		return r.Render(memory)
+	}
+	data, err := client.ReadMemory(int(start), *n)
+	if err != nil {
+		return err
+	}
+	dumper := hex.Dumper(os.Stdout)
+	defer dumper.Close()
+	_, err = dumper.Write(data)
+	return err
+}
+
+// runRaw sends a raw command, e.g. "?F", and prints the response
+func runRaw(args []string) error {
+	var device deviceFlags
+	fs := flag.NewFlagSet("raw", flag.ContinueOnError)
+	device.register(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return usagef("usage: gpira raw [flags] <command>")
+	}
+
+	client, err := device.dial()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	response, err := client.Exec(pira.Command(fs.Arg(0)))
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Print(string(response))
+	return err
+}
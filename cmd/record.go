@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go-pira/pkg/pira"
+	"go-pira/pkg/record"
+)
+
+// runRecord logs snapshots to a rotating NDJSON or CSV file
+func runRecord(args []string) error {
+	var device deviceFlags
+	fs := flag.NewFlagSet("record", flag.ContinueOnError)
+	device.register(fs)
+	path := fs.String("file", "pira.ndjson", "log file")
+	format := fs.String("format", "", "ndjson or csv, defaults to the file extension")
+	maxSize := fs.Int64("max-size", 64, "rotate after this many MiB, 0 disables")
+	maxAge := fs.Duration("max-age", 24*time.Hour, "rotate after this time, 0 disables")
+	compress := fs.Bool("compress", true, "gzip rotated files")
+	sync := fs.Duration("sync", 2*time.Second, "fsync interval")
+	interval := fs.Duration("interval", time.Second, "poll interval")
+	fast := fs.Duration("fast", 0, "deviation poll interval, 0 disables")
+	basic := fs.Bool("basic", false, "also read the basic data")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *format == "" {
+		*format = strings.TrimPrefix(filepath.Ext(*path), ".")
+	}
+	f, err := record.ParseFormat(*format)
+	if err != nil {
+		return &usageError{err}
+	}
+
+	client, err := device.dial()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	file, err := record.OpenFile(record.FileOptions{
+		Path:         *path,
+		MaxSize:      *maxSize << 20,
+		MaxAge:       *maxAge,
+		Compress:     *compress,
+		SyncInterval: *sync,
+		Header:       f.Header(),
+		RecordTime:   f.RecordTime,
+	})
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	w := record.NewWriter(file, f)
+	monitor := pira.NewMonitor(client, pira.MonitorConfig{SlowInterval: *interval, FastInterval: *fast, BasicData: *basic})
+	snapshots := monitor.Snapshots(ctx)
+	go monitor.Run(ctx)
+	for s := range snapshots {
+		if err := w.Write(s); err != nil {
+			slog.Warn("failed to write log", "file", *path, "error", err)
+		}
+	}
+	return nil
+}
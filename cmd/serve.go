@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"go-pira/pkg/pira"
+	"go-pira/pkg/server"
+)
+
+// runServe shares the analyzer through the HTTP JSON API and streams the
+// readings of a single monitor to server-sent event clients
+func runServe(args []string) error {
+	var device deviceFlags
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	device.register(fs)
+	listen := fs.String("listen", "127.0.0.1:8080", "HTTP listen address")
+	token := fs.String("token", os.Getenv("GPIRA_TOKEN"), "bearer token of the tune and hold reset routes, empty disables them ($GPIRA_TOKEN)")
+	interval := fs.Duration("interval", time.Second, "stream poll interval")
+	fast := fs.Duration("fast", 0, "stream deviation poll interval, 0 disables")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	client, err := device.dial()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	monitor := pira.NewMonitor(client, pira.MonitorConfig{SlowInterval: *interval, FastInterval: *fast})
+	hub := server.NewHub(pira.DifferConfig{})
+	// Snapshots subscribes before Run publishes the first snapshot
+	go hub.Run(ctx, monitor.Snapshots(ctx))
+	go monitor.Run(ctx)
+
+	api := server.New(client)
+	api.ControlToken = *token
+	api.HandleStream(hub)
+	httpServer := &http.Server{Addr: *listen, Handler: api}
+	go func() {
+		<-ctx.Done()
+		httpServer.Close()
+	}()
+	slog.Info("serving api", "address", *listen)
+	if err := httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
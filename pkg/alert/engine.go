@@ -0,0 +1,266 @@
+package alert
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"go-pira/pkg/expr"
+)
+
+// ErrUnknownAlert is returned when acknowledging a rule that is not active
+var ErrUnknownAlert = errors.New("no active alert")
+
+// State is the state of a rule
+type State int
+
+const (
+	// StateOK is a rule within its thresholds
+	StateOK State = iota
+	// StatePending is a violated rule waiting for its hold time
+	StatePending
+	// StateActive is a firing rule
+	StateActive
+	// StateClearing is an active rule back within its thresholds waiting
+	// for its clear hold time
+	StateClearing
+)
+
+var stateNames = [...]string{"ok", "pending", "active", "clearing"}
+
+func (s State) String() string {
+	if s < 0 || int(s) >= len(stateNames) {
+		return fmt.Sprintf("state %d", s)
+	}
+	return stateNames[s]
+}
+
+// MarshalText implements the encoding.TextMarshaler interface for State
+func (s State) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// EventType is the transition reported by an Event
+type EventType string
+
+const (
+	EventActive       EventType = "active"
+	EventCleared      EventType = "cleared"
+	EventAcknowledged EventType = "acknowledged"
+)
+
+// Alert is the state of a rule
+type Alert struct {
+	Rule         string    `json:"rule"`
+	Description  string    `json:"description,omitempty"`
+	Metric       string    `json:"metric"`
+	Severity     Severity  `json:"severity"`
+	State        State     `json:"state"`
+	Value        float64   `json:"value"`
+	Since        time.Time `json:"since"`
+	Acknowledged bool      `json:"acknowledged"`
+}
+
+// Event is an alert transition
+type Event struct {
+	Type  EventType `json:"type"`
+	Time  time.Time `json:"time"`
+	Alert Alert     `json:"alert"`
+}
+
+type ruleState struct {
+	rule      Rule
+	expr      *expr.Program
+	clearExpr *expr.Program
+	alert     Alert
+	// changed is when the pending or clearing state started
+	changed time.Time
+}
+
+// Engine evaluates rules against metric readings and sends the
+// transitions to its sinks. It is safe for concurrent use.
+type Engine struct {
+	mu    sync.Mutex
+	rules []*ruleState
+	sinks []Sink
+}
+
+// NewEngine returns an engine for rules, rule names must be unique
+func NewEngine(rules []Rule, sinks ...Sink) (*Engine, error) {
+	e := &Engine{sinks: sinks}
+	names := make(map[string]bool)
+	for _, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return nil, err
+		}
+		if names[rule.Name] {
+			return nil, fmt.Errorf("duplicate rule: %s", rule.Name)
+		}
+		names[rule.Name] = true
+		violation, clearing, err := rule.compile()
+		if err != nil {
+			return nil, err
+		}
+		e.rules = append(e.rules, &ruleState{
+			rule:      rule,
+			expr:      violation,
+			clearExpr: clearing,
+			alert: Alert{
+				Rule:        rule.Name,
+				Description: rule.Description,
+				Metric:      rule.Metric,
+				Severity:    rule.Severity,
+			},
+		})
+	}
+	return e, nil
+}
+
+// Evaluate updates the rules with the variables read at time at and
+// returns the transitions. Threshold rules read their metric as a number,
+// see Vars. Rules whose variables are missing keep their state, other
+// expression errors are joined into err together with sink errors.
+// Events are sent to every sink regardless.
+func (e *Engine) Evaluate(at time.Time, vars map[string]any) (events []Event, err error) {
+	var errs []error
+	e.mu.Lock()
+	for _, rs := range e.rules {
+		active := rs.alert.State == StateActive || rs.alert.State == StateClearing
+		violated, err := rs.violated(vars, active)
+		if err != nil {
+			if !errors.Is(err, errSkip) && !errors.Is(err, expr.ErrUndefined) {
+				errs = append(errs, fmt.Errorf("rule %s: %w", rs.rule.Name, err))
+			}
+			continue
+		}
+		if value, ok := number(vars[rs.rule.Metric]); ok {
+			rs.alert.Value = value
+		}
+		if event, ok := rs.update(at, active, violated); ok {
+			events = append(events, event)
+		}
+	}
+	e.mu.Unlock()
+	return events, errors.Join(append(errs, e.send(events))...)
+}
+
+// errSkip is returned by violated when the metric is missing
+var errSkip = errors.New("metric missing")
+
+func (rs *ruleState) violated(vars map[string]any, active bool) (bool, error) {
+	if rs.expr == nil {
+		value, ok := number(vars[rs.rule.Metric])
+		if !ok {
+			return false, errSkip
+		}
+		return rs.rule.violated(value, active), nil
+	}
+	if active && rs.clearExpr != nil {
+		cleared, err := rs.clearExpr.Bool(vars)
+		return !cleared, err
+	}
+	return rs.expr.Bool(vars)
+}
+
+// number converts a numeric variable to float64
+func number(v any) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// update steps the state machine of a rule
+func (rs *ruleState) update(at time.Time, active, violated bool) (Event, bool) {
+	a := &rs.alert
+	switch {
+	case !active && !violated:
+		a.State = StateOK
+	case !active && violated:
+		if a.State == StateOK {
+			a.State, rs.changed = StatePending, at
+		}
+		if at.Sub(rs.changed) >= time.Duration(rs.rule.For) {
+			a.State, a.Since, a.Acknowledged = StateActive, at, false
+			return Event{Type: EventActive, Time: at, Alert: *a}, true
+		}
+	case active && violated:
+		a.State = StateActive
+	case active && !violated:
+		if a.State == StateActive {
+			a.State, rs.changed = StateClearing, at
+		}
+		if at.Sub(rs.changed) >= time.Duration(rs.rule.ClearFor) {
+			a.State, a.Since = StateOK, at
+			event := Event{Type: EventCleared, Time: at, Alert: *a}
+			a.Acknowledged = false
+			return event, true
+		}
+	}
+	return Event{}, false
+}
+
+// Acknowledge marks the active alert of the named rule as acknowledged,
+// the acknowledgement lasts until the alert clears
+func (e *Engine) Acknowledge(at time.Time, name string) error {
+	e.mu.Lock()
+	i := slices.IndexFunc(e.rules, func(rs *ruleState) bool { return rs.rule.Name == name })
+	if i < 0 || (e.rules[i].alert.State != StateActive && e.rules[i].alert.State != StateClearing) {
+		e.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrUnknownAlert, name)
+	}
+	a := &e.rules[i].alert
+	if a.Acknowledged {
+		e.mu.Unlock()
+		return nil
+	}
+	a.Acknowledged = true
+	event := Event{Type: EventAcknowledged, Time: at, Alert: *a}
+	e.mu.Unlock()
+	return e.send([]Event{event})
+}
+
+// Alerts returns the state of every rule in rule order
+func (e *Engine) Alerts() []Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	alerts := make([]Alert, len(e.rules))
+	for i, rs := range e.rules {
+		alerts[i] = rs.alert
+	}
+	return alerts
+}
+
+// Active returns the alerts that are firing
+func (e *Engine) Active() []Alert {
+	return slices.DeleteFunc(e.Alerts(), func(a Alert) bool {
+		return a.State != StateActive && a.State != StateClearing
+	})
+}
+
+func (e *Engine) send(events []Event) error {
+	var errs []error
+	for _, event := range events {
+		for _, sink := range e.sinks {
+			if err := sink.Send(event); err != nil {
+				errs = append(errs, fmt.Errorf("failed to send alert %s: %w", event.Alert.Rule, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
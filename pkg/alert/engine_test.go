@@ -0,0 +1,171 @@
+package alert
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go-pira/pkg/pira"
+)
+
+func TestEngine_Evaluate(t *testing.T) {
+	limit := 75000.0
+	rule := Rule{
+		Name:       "peak",
+		Metric:     "deviation_max",
+		Above:      &limit,
+		Hysteresis: 1000,
+		For:        Duration(2 * time.Second),
+		ClearFor:   Duration(2 * time.Second),
+		Severity:   SeverityCritical,
+	}
+	var sent []EventType
+	engine, err := NewEngine([]Rule{rule}, SinkFunc(func(e Event) error {
+		sent = append(sent, e.Type)
+		return nil
+	}))
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	steps := []struct {
+		value     float64
+		wantState State
+		wantEvent EventType
+	}{
+		{value: 70000, wantState: StateOK},
+		{value: 80000, wantState: StatePending},
+		{value: 70000, wantState: StateOK},
+		{value: 80000, wantState: StatePending},
+		{value: 80000, wantState: StatePending},
+		{value: 80000, wantState: StateActive, wantEvent: EventActive},
+		{value: 74500, wantState: StateActive},
+		{value: 73000, wantState: StateClearing},
+		{value: 76000, wantState: StateActive},
+		{value: 73000, wantState: StateClearing},
+		{value: 73000, wantState: StateClearing},
+		{value: 73000, wantState: StateOK, wantEvent: EventCleared},
+	}
+	var want []EventType
+	for i, step := range steps {
+		at := start.Add(time.Duration(i) * time.Second)
+		events, err := engine.Evaluate(at, map[string]any{"deviation_max": step.value})
+		if err != nil {
+			t.Fatalf("step %d: Evaluate() error = %v", i, err)
+		}
+		var got EventType
+		if len(events) > 0 {
+			got = events[0].Type
+			want = append(want, got)
+		}
+		if got != step.wantEvent {
+			t.Errorf("step %d: Evaluate(%v) event = %q, want %q", i, step.value, got, step.wantEvent)
+		}
+		if state := engine.Alerts()[0].State; state != step.wantState {
+			t.Errorf("step %d: Evaluate(%v) state = %v, want %v", i, step.value, state, step.wantState)
+		}
+	}
+	if !reflect.DeepEqual(sent, want) {
+		t.Errorf("sink received %v, want %v", sent, want)
+	}
+}
+
+func TestEngine_Acknowledge(t *testing.T) {
+	limit := 1000.0
+	engine, err := NewEngine([]Rule{{Name: "rds", Metric: "rds_deviation", Below: &limit}})
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+	now := time.Now()
+	if err := engine.Acknowledge(now, "rds"); !errors.Is(err, ErrUnknownAlert) {
+		t.Errorf("Acknowledge() inactive error = %v, want %v", err, ErrUnknownAlert)
+	}
+
+	engine.Evaluate(now, map[string]any{"rds_deviation": 0})
+	if err := engine.Acknowledge(now, "rds"); err != nil {
+		t.Fatalf("Acknowledge() error = %v", err)
+	}
+	active := engine.Active()
+	if len(active) != 1 || !active[0].Acknowledged {
+		t.Errorf("Active() = %+v, want one acknowledged alert", active)
+	}
+
+	engine.Evaluate(now, map[string]any{"rds_deviation": 3000})
+	engine.Evaluate(now, map[string]any{"rds_deviation": 0})
+	if active := engine.Active(); len(active) != 1 || active[0].Acknowledged {
+		t.Errorf("Active() after refire = %+v, want unacknowledged alert", active)
+	}
+}
+
+func TestEngine_MissingMetric(t *testing.T) {
+	limit := 50.0
+	engine, _ := NewEngine([]Rule{{Name: "quality", Metric: "signal_quality", Below: &limit}})
+	events, err := engine.Evaluate(time.Now(), map[string]any{})
+	if err != nil || len(events) != 0 {
+		t.Errorf("Evaluate() = %v, %v, want no events", events, err)
+	}
+}
+
+func TestNewEngine_Duplicate(t *testing.T) {
+	limit := 50.0
+	r := Rule{Name: "quality", Metric: "signal_quality", Below: &limit}
+	if _, err := NewEngine([]Rule{r, r}); err == nil {
+		t.Error("NewEngine() with duplicate rules, want error")
+	}
+}
+
+func TestEngine_Expr(t *testing.T) {
+	rules, err := ReadRules(strings.NewReader(`[
+		{"name": "weak rds", "expr": "rds_deviation < 2000 && stereo", "severity": "warning"},
+		{"name": "unexpected ps", "expr": "rds.ps not in ['RADIO 1', 'NEWS']", "clear_expr": "rds.ps == 'RADIO 1'"}
+	]`))
+	if err != nil {
+		t.Fatalf("ReadRules() error = %v", err)
+	}
+	engine, err := NewEngine(rules)
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+
+	fmi := pira.FMInfo{PilotDeviation: 6800, RDSDeviation: 1500, RDS: pira.RDSInfo{PS: "RADIO 1 "}}
+	steps := []struct {
+		update func()
+		want   []string
+	}{
+		{update: func() {}, want: []string{"weak rds"}},
+		{update: func() { fmi.RDS.PS = "NEWS    " }, want: []string{"weak rds"}},
+		{update: func() { fmi.RDS.PS = "CRASH   " }, want: []string{"weak rds", "unexpected ps"}},
+		// the clear expression keeps the alert active on the other allowed PS
+		{update: func() { fmi.RDS.PS = "NEWS    "; fmi.PilotDeviation = 0 }, want: []string{"unexpected ps"}},
+		{update: func() { fmi.RDS.PS = "RADIO 1 " }, want: nil},
+	}
+	for i, step := range steps {
+		step.update()
+		if _, err := engine.Evaluate(time.Now(), Vars(&fmi, nil)); err != nil {
+			t.Fatalf("step %d: Evaluate() error = %v", i, err)
+		}
+		var got []string
+		for _, a := range engine.Active() {
+			got = append(got, a.Rule)
+		}
+		if !reflect.DeepEqual(got, step.want) {
+			t.Errorf("step %d: Active() = %v, want %v", i, got, step.want)
+		}
+	}
+}
+
+func TestEngine_ExprError(t *testing.T) {
+	engine, err := NewEngine([]Rule{{Name: "typo", Expr: "rds.ps > 1"}})
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+	if _, err := engine.Evaluate(time.Now(), map[string]any{"rds.ps": "RADIO"}); err == nil {
+		t.Error("Evaluate() error = nil, want type error")
+	}
+	if _, err := engine.Evaluate(time.Now(), map[string]any{}); err != nil {
+		t.Errorf("Evaluate() with missing variable error = %v, want nil", err)
+	}
+}
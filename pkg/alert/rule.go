@@ -0,0 +1,168 @@
+// Package alert evaluates threshold rules over analyzer metrics
+package alert
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"go-pira/pkg/expr"
+)
+
+// Severity is the importance of an alert
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityCritical
+)
+
+var severityNames = [...]string{"info", "warning", "critical"}
+
+func (s Severity) String() string {
+	if s < 0 || int(s) >= len(severityNames) {
+		return fmt.Sprintf("severity %d", s)
+	}
+	return severityNames[s]
+}
+
+// MarshalText implements the encoding.TextMarshaler interface for Severity
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface for Severity
+func (s *Severity) UnmarshalText(text []byte) error {
+	for i, name := range severityNames {
+		if strings.EqualFold(string(text), name) {
+			*s = Severity(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown severity: %s", text)
+}
+
+// Duration is a time.Duration read from JSON as a string like "30s"
+type Duration time.Duration
+
+// MarshalText implements the encoding.TextMarshaler interface for Duration
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface for Duration
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Rule fires when a metric is above Above or below Below for at least
+// For. Setting both watches a window, the pilot deviation leaving
+// 6-7.5 kHz is {Below: 6000, Above: 7500}. An active alert clears once
+// the metric is back inside by Hysteresis for at least ClearFor.
+//
+// Expr replaces the thresholds with an expression over the variables
+// returned by Vars, the rule is violated while it is true. An active
+// expression rule clears when ClearExpr is true, or when Expr is false
+// without a ClearExpr. Metric is then only used as the reported value.
+type Rule struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Metric      string   `json:"metric"`
+	Above       *float64 `json:"above,omitempty"`
+	Below       *float64 `json:"below,omitempty"`
+	Expr        string   `json:"expr,omitempty"`
+	ClearExpr   string   `json:"clear_expr,omitempty"`
+	Hysteresis  float64  `json:"hysteresis,omitempty"`
+	For         Duration `json:"for,omitempty"`
+	ClearFor    Duration `json:"clear_for,omitempty"`
+	Severity    Severity `json:"severity"`
+}
+
+// Validate checks the rule is complete
+func (r *Rule) Validate() error {
+	switch {
+	case r.Name == "":
+		return errors.New("rule without name")
+	case r.Expr != "" && (r.Above != nil || r.Below != nil):
+		return fmt.Errorf("rule %s: expression and thresholds are exclusive", r.Name)
+	case r.Expr == "" && r.ClearExpr != "":
+		return fmt.Errorf("rule %s: clear expression without expression", r.Name)
+	case r.Expr == "" && r.Metric == "":
+		return fmt.Errorf("rule %s: missing metric", r.Name)
+	case r.Expr == "" && r.Above == nil && r.Below == nil:
+		return fmt.Errorf("rule %s: missing above or below threshold", r.Name)
+	case r.Above != nil && r.Below != nil && *r.Below >= *r.Above:
+		return fmt.Errorf("rule %s: below threshold must be lower than above", r.Name)
+	case r.Hysteresis < 0 || r.For < 0 || r.ClearFor < 0:
+		return fmt.Errorf("rule %s: negative hysteresis or hold time", r.Name)
+	}
+	_, _, err := r.compile()
+	return err
+}
+
+// compile compiles the expressions, nil when not set
+func (r *Rule) compile() (violation, clearing *expr.Program, err error) {
+	if r.Expr != "" {
+		if violation, err = expr.Compile(r.Expr); err != nil {
+			return nil, nil, fmt.Errorf("rule %s: expr: %w", r.Name, err)
+		}
+	}
+	if r.ClearExpr != "" {
+		if clearing, err = expr.Compile(r.ClearExpr); err != nil {
+			return nil, nil, fmt.Errorf("rule %s: clear_expr: %w", r.Name, err)
+		}
+	}
+	return violation, clearing, nil
+}
+
+// violated reports whether value is outside the thresholds. An active
+// rule stays violated until the value is inside by the hysteresis.
+func (r *Rule) violated(value float64, active bool) bool {
+	margin := 0.0
+	if active {
+		margin = r.Hysteresis
+	}
+	if r.Above != nil && value > *r.Above-margin {
+		return true
+	}
+	if r.Below != nil && value < *r.Below+margin {
+		return true
+	}
+	return false
+}
+
+// ReadRules reads a JSON array of rules
+func ReadRules(r io.Reader) ([]Rule, error) {
+	var rules []Rule
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&rules); err != nil {
+		return nil, fmt.Errorf("failed to read alert rules: %w", err)
+	}
+	for i := range rules {
+		if err := rules[i].Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return rules, nil
+}
+
+// LoadRules reads the rules from a JSON file
+func LoadRules(path string) ([]Rule, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadRules(f)
+}
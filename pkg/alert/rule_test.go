@@ -0,0 +1,94 @@
+package alert
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{
+			name:  "window",
+			input: `[{"name":"pilot","metric":"pilot_deviation","below":6000,"above":7500,"hysteresis":100,"for":"5s","clear_for":"30s","severity":"warning"}]`,
+		},
+		{
+			name:    "missing threshold",
+			input:   `[{"name":"pilot","metric":"pilot_deviation"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "inverted window",
+			input:   `[{"name":"pilot","metric":"pilot_deviation","below":7500,"above":6000}]`,
+			wantErr: true,
+		},
+		{
+			name:    "bad duration",
+			input:   `[{"name":"peak","metric":"deviation_max","above":75000,"for":"soon"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "bad severity",
+			input:   `[{"name":"peak","metric":"deviation_max","above":75000,"severity":"panic"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "bad expression",
+			input:   `[{"name":"ps","expr":"rds.ps ==","severity":"info"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "expression and threshold",
+			input:   `[{"name":"ps","expr":"stereo","above":1}]`,
+			wantErr: true,
+		},
+		{
+			name:    "unknown field",
+			input:   `[{"name":"peak","metric":"deviation_max","above":75000,"treshold":1}]`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := ReadRules(strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadRules() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			r := rules[0]
+			if *r.Below != 6000 || *r.Above != 7500 || r.For != Duration(5*time.Second) || r.ClearFor != Duration(30*time.Second) || r.Severity != SeverityWarning {
+				t.Errorf("ReadRules() = %+v", r)
+			}
+		})
+	}
+}
+
+func TestRule_Violated(t *testing.T) {
+	above, below := 7500.0, 6000.0
+	r := Rule{Above: &above, Below: &below, Hysteresis: 100}
+	tests := []struct {
+		value  float64
+		active bool
+		want   bool
+	}{
+		{value: 6800, want: false},
+		{value: 7600, want: true},
+		{value: 5900, want: true},
+		{value: 7450, active: false, want: false},
+		{value: 7450, active: true, want: true},
+		{value: 6050, active: true, want: true},
+		{value: 7300, active: true, want: false},
+	}
+	for _, tt := range tests {
+		if got := r.violated(tt.value, tt.active); got != tt.want {
+			t.Errorf("Rule.violated(%v, %v) = %v, want %v", tt.value, tt.active, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"sync"
+)
+
+// Sink receives alert events
+type Sink interface {
+	Send(e Event) error
+}
+
+// SinkFunc adapts a function to the Sink interface
+type SinkFunc func(e Event) error
+
+// Send calls f(e)
+func (f SinkFunc) Send(e Event) error {
+	return f(e)
+}
+
+// JSONSink writes events as newline delimited JSON
+type JSONSink struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+// NewJSONSink returns a sink writing to w
+func NewJSONSink(w io.Writer) *JSONSink {
+	return &JSONSink{encoder: json.NewEncoder(w)}
+}
+
+// Send implements the Sink interface for JSONSink
+func (s *JSONSink) Send(e Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.encoder.Encode(e)
+}
+
+// LogSink logs events with slog, critical alerts at error level
+type LogSink struct {
+	Logger *slog.Logger
+}
+
+// Send implements the Sink interface for LogSink
+func (s LogSink) Send(e Event) error {
+	logger := s.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	level := slog.LevelInfo
+	if e.Type == EventActive {
+		level = slog.LevelWarn
+		if e.Alert.Severity == SeverityCritical {
+			level = slog.LevelError
+		}
+	}
+	logger.Log(context.Background(), level, "alert "+string(e.Type),
+		"rule", e.Alert.Rule,
+		"severity", e.Alert.Severity,
+		"metric", e.Alert.Metric,
+		"value", e.Alert.Value)
+	return nil
+}
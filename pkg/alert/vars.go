@@ -0,0 +1,51 @@
+package alert
+
+import (
+	"strings"
+
+	"go-pira/pkg/pira"
+)
+
+// Vars returns the variables available to rules: the metrics of fmi and
+// bd by the pira metric names, the RDS fields as rds.pi, rds.ps, rds.rt,
+// rds.pty, rds.pty_name, rds.ta, rds.tp, rds.ms and rds.country, and the
+// derived stereo and rds_present flags. Text fields have their padding
+// removed. FMInfo values take precedence, either argument may be nil.
+func Vars(fmi *pira.FMInfo, bd *pira.BasicData) map[string]any {
+	vars := make(map[string]any)
+	if bd != nil {
+		for name, value := range bd.Metrics() {
+			vars[name] = value
+		}
+	}
+	if fmi != nil {
+		for name, value := range fmi.Metrics() {
+			vars[name] = value
+		}
+		rds := &fmi.RDS
+		vars["rds.pi"] = rds.PI.String()
+		vars["rds.ps"] = trimText(rds.PS)
+		vars["rds.rt"] = trimText(rds.RT)
+		vars["rds.pty"] = float64(rds.PTY.Code)
+		vars["rds.pty_name"] = rds.PTY.String()
+		vars["rds.ta"] = rds.Status.TA
+		vars["rds.tp"] = rds.Status.TP
+		vars["rds.ms"] = rds.Status.MS
+		vars["rds.country"] = rds.PIInfo.Country
+	}
+	if pilot, ok := vars[pira.MetricPilotDeviation].(float64); ok {
+		vars["stereo"] = pilot >= pira.DefaultPilotThreshold
+	}
+	if deviation, ok := vars[pira.MetricRDSDeviation].(float64); ok {
+		vars["rds_present"] = deviation >= pira.DefaultRDSThreshold
+	}
+	return vars
+}
+
+// trimText cuts RDS text at the carriage return and removes the padding
+func trimText(s string) string {
+	if cr := strings.IndexByte(s, '\r'); cr >= 0 {
+		s = s[:cr]
+	}
+	return strings.TrimSpace(strings.Trim(s, "\x00"))
+}
@@ -0,0 +1,253 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
+
+func (l literal) eval(Env) (any, error) {
+	return l.value, nil
+}
+
+func (v variable) eval(env Env) (any, error) {
+	value, ok := env[v.name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUndefined, v.name)
+	}
+	return normalizeValue(value)
+}
+
+func (l list) eval(env Env) (any, error) {
+	items := make([]any, len(l.items))
+	for i, item := range l.items {
+		v, err := item.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		items[i] = v
+	}
+	return items, nil
+}
+
+func (u unary) eval(env Env) (any, error) {
+	x, err := u.x.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	switch u.op {
+	case "!":
+		b, ok := x.(bool)
+		if !ok {
+			return nil, typeError(u.op, x)
+		}
+		return !b, nil
+	default:
+		f, ok := x.(float64)
+		if !ok {
+			return nil, typeError(u.op, x)
+		}
+		return -f, nil
+	}
+}
+
+func (b binary) eval(env Env) (any, error) {
+	x, err := b.x.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	// && and || short circuit
+	if b.op == "&&" || b.op == "||" {
+		xb, ok := x.(bool)
+		if !ok {
+			return nil, typeError(b.op, x)
+		}
+		if xb == (b.op == "||") {
+			return xb, nil
+		}
+		y, err := b.y.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		yb, ok := y.(bool)
+		if !ok {
+			return nil, typeError(b.op, y)
+		}
+		return yb, nil
+	}
+
+	y, err := b.y.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	switch b.op {
+	case "==":
+		return equal(x, y), nil
+	case "!=":
+		return !equal(x, y), nil
+	case "in", "not in":
+		items, ok := y.([]any)
+		if !ok {
+			// substring test
+			if xs, ok := x.(string); ok {
+				if ys, ok := y.(string); ok {
+					return strings.Contains(ys, xs) == (b.op == "in"), nil
+				}
+			}
+			return nil, typeError(b.op, x, y)
+		}
+		found := slices.ContainsFunc(items, func(item any) bool { return equal(x, item) })
+		return found == (b.op == "in"), nil
+	}
+
+	if xs, ok := x.(string); ok {
+		ys, ok := y.(string)
+		if !ok {
+			return nil, typeError(b.op, x, y)
+		}
+		switch b.op {
+		case "+":
+			return xs + ys, nil
+		case "<":
+			return xs < ys, nil
+		case "<=":
+			return xs <= ys, nil
+		case ">":
+			return xs > ys, nil
+		case ">=":
+			return xs >= ys, nil
+		}
+		return nil, typeError(b.op, x, y)
+	}
+
+	xf, xok := x.(float64)
+	yf, yok := y.(float64)
+	if !xok || !yok {
+		return nil, typeError(b.op, x, y)
+	}
+	switch b.op {
+	case "+":
+		return xf + yf, nil
+	case "-":
+		return xf - yf, nil
+	case "*":
+		return xf * yf, nil
+	case "/":
+		return xf / yf, nil
+	case "%":
+		return math.Mod(xf, yf), nil
+	case "<":
+		return xf < yf, nil
+	case "<=":
+		return xf <= yf, nil
+	case ">":
+		return xf > yf, nil
+	case ">=":
+		return xf >= yf, nil
+	}
+	return nil, fmt.Errorf("unknown operator %s", b.op)
+}
+
+func (c call) eval(env Env) (any, error) {
+	args := make([]any, len(c.args))
+	for i, arg := range c.args {
+		v, err := arg.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+	v, err := c.fn.call(args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", c.name, err)
+	}
+	return v, nil
+}
+
+func equal(x, y any) bool {
+	switch x := x.(type) {
+	case []any:
+		y, ok := y.([]any)
+		return ok && slices.EqualFunc(x, y, equal)
+	default:
+		return x == y
+	}
+}
+
+// normalizeValue converts env values to the types used by the evaluator
+func normalizeValue(v any) (any, error) {
+	switch v := v.(type) {
+	case float64, string, bool:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	case int8:
+		return float64(v), nil
+	case int16:
+		return float64(v), nil
+	case int32:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case uint:
+		return float64(v), nil
+	case uint8:
+		return float64(v), nil
+	case uint16:
+		return float64(v), nil
+	case uint32:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
+	case fmt.Stringer:
+		return v.String(), nil
+	case []string:
+		items := make([]any, len(v))
+		for i, s := range v {
+			items[i] = s
+		}
+		return items, nil
+	case []float64:
+		items := make([]any, len(v))
+		for i, f := range v {
+			items[i] = f
+		}
+		return items, nil
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			n, err := normalizeValue(item)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = n
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("unsupported value type %T", v)
+}
+
+func typeName(v any) string {
+	switch v.(type) {
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case []any:
+		return "list"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func typeError(op string, operands ...any) error {
+	names := make([]string, len(operands))
+	for i, v := range operands {
+		names[i] = typeName(v)
+	}
+	return fmt.Errorf("invalid operation: %s on %s", op, strings.Join(names, " and "))
+}
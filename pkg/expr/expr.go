@@ -0,0 +1,99 @@
+// Package expr implements a small expression language for user defined
+// rules. Expressions are side effect free and cannot loop, they combine
+// numbers, strings and booleans with the usual operators:
+//
+//	rds_deviation < 2000 && pilot_deviation > 3000
+//	trim(rds.ps) not in ["RADIO 1", "NEWS"]
+//	abs(rds_phase_difference) > 10 or !rds.tp
+//
+// Variables are looked up by name in an Env, names may contain dots.
+package expr
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUndefined is returned when an expression uses a variable missing from the Env
+var ErrUndefined = errors.New("undefined variable")
+
+// SyntaxError is a compile error at byte offset Pos
+type SyntaxError struct {
+	Pos int
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at %d: %s", e.Pos, e.Msg)
+}
+
+// Env holds the variables of an evaluation. Values are float64, string,
+// bool or []any of those, other numeric types are converted to float64.
+type Env map[string]any
+
+// Program is a compiled expression, safe for concurrent use
+type Program struct {
+	src  string
+	root node
+}
+
+// Compile parses src
+func Compile(src string) (*Program, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenEOF {
+		return nil, p.errorf("unexpected token")
+	}
+	return &Program{src: src, root: root}, nil
+}
+
+// MustCompile is like Compile but panics on error
+func MustCompile(src string) *Program {
+	p, err := Compile(src)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+func (p *Program) String() string {
+	return p.src
+}
+
+// Eval evaluates the program against env
+func (p *Program) Eval(env Env) (any, error) {
+	return p.root.eval(env)
+}
+
+// Bool evaluates a program that must yield a boolean
+func (p *Program) Bool(env Env) (bool, error) {
+	v, err := p.Eval(env)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("expression %q: result is %s, want bool", p.src, typeName(v))
+	}
+	return b, nil
+}
+
+// Float evaluates a program that must yield a number
+func (p *Program) Float(env Env) (float64, error) {
+	v, err := p.Eval(env)
+	if err != nil {
+		return 0, err
+	}
+	f, ok := v.(float64)
+	if !ok {
+		return 0, fmt.Errorf("expression %q: result is %s, want number", p.src, typeName(v))
+	}
+	return f, nil
+}
@@ -0,0 +1,117 @@
+package expr
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestProgram_Eval(t *testing.T) {
+	env := Env{
+		"rds_deviation":        1500.0,
+		"pilot_deviation":      uint32(6800),
+		"rds_phase_difference": int16(-12),
+		"rds.ps":               "RADIO 1 ",
+		"rds.tp":               true,
+	}
+	tests := []struct {
+		src  string
+		want any
+	}{
+		{src: "1 + 2 * 3", want: 7.0},
+		{src: "(1 + 2) * 3", want: 9.0},
+		{src: "-2 - -3", want: 1.0},
+		{src: "7 % 4", want: 3.0},
+		{src: "1.5e3", want: 1500.0},
+		{src: "rds_deviation < 2000 && pilot_deviation > 3000", want: true},
+		{src: "rds_deviation < 2000 and not rds.tp", want: false},
+		{src: "abs(rds_phase_difference) > 10 || false", want: true},
+		{src: `trim(rds.ps) in ["RADIO 1", "NEWS"]`, want: true},
+		{src: `trim(rds.ps) not in ['RADIO 1', 'NEWS']`, want: false},
+		{src: `"DIO" in rds.ps`, want: true},
+		{src: `rds.ps == "RADIO 1 "`, want: true},
+		{src: `lower(trim(rds.ps)) + "!"`, want: "radio 1!"},
+		{src: "max(1, 5, 3) - min(4, 2)", want: 3.0},
+		{src: "len(rds.ps)", want: 8.0},
+		{src: `contains(rds.ps, "1") && prefix(rds.ps, "RA")`, want: true},
+		{src: "[1, 2] == [1, 2]", want: true},
+		// short circuit skips the undefined variable
+		{src: "false && missing > 1", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			p, err := Compile(tt.src)
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			got, err := p.Eval(env)
+			if err != nil {
+				t.Fatalf("Eval() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Eval() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	tests := []string{
+		"",
+		"1 +",
+		"(1 + 2",
+		"1 2",
+		`"open`,
+		"a # b",
+		"exec(1)",
+		"abs(1, 2)",
+		"[1, 2",
+		"a and",
+	}
+	for _, src := range tests {
+		t.Run(src, func(t *testing.T) {
+			_, err := Compile(src)
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Errorf("Compile(%q) error = %v, want SyntaxError", src, err)
+			}
+		})
+	}
+}
+
+func TestProgram_EvalErrors(t *testing.T) {
+	env := Env{"ps": "RADIO", "ta": true}
+	tests := []struct {
+		src       string
+		undefined bool
+	}{
+		{src: "missing > 1", undefined: true},
+		{src: "ps > 1"},
+		{src: "ta + 1"},
+		{src: "!ps"},
+		{src: "abs(ps)"},
+		{src: "ps && ta"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			_, err := MustCompile(tt.src).Eval(env)
+			if err == nil {
+				t.Fatal("Eval() error = nil, want error")
+			}
+			if errors.Is(err, ErrUndefined) != tt.undefined {
+				t.Errorf("Eval() error = %v, undefined %v", err, tt.undefined)
+			}
+		})
+	}
+}
+
+func TestProgram_Bool(t *testing.T) {
+	if _, err := MustCompile("1 + 1").Bool(nil); err == nil {
+		t.Error("Bool() on a number, want error")
+	}
+	got, err := MustCompile("1 < 2").Bool(nil)
+	if err != nil || !got {
+		t.Errorf("Bool() = %v, %v, want true", got, err)
+	}
+}
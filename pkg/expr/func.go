@@ -0,0 +1,86 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+type function struct {
+	minArgs, maxArgs int // maxArgs -1 is variadic
+	call             func(args []any) (any, error)
+}
+
+// functions are the builtins available to expressions
+var functions = map[string]function{
+	"abs":      {1, 1, numeric(math.Abs)},
+	"round":    {1, 1, numeric(math.Round)},
+	"min":      {1, -1, fold(math.Min)},
+	"max":      {1, -1, fold(math.Max)},
+	"trim":     {1, 1, text(strings.TrimSpace)},
+	"lower":    {1, 1, text(strings.ToLower)},
+	"upper":    {1, 1, text(strings.ToUpper)},
+	"len":      {1, 1, length},
+	"contains": {2, 2, stringPredicate(strings.Contains)},
+	"prefix":   {2, 2, stringPredicate(strings.HasPrefix)},
+	"suffix":   {2, 2, stringPredicate(strings.HasSuffix)},
+}
+
+func numeric(f func(float64) float64) func([]any) (any, error) {
+	return func(args []any) (any, error) {
+		x, ok := args[0].(float64)
+		if !ok {
+			return nil, fmt.Errorf("argument is %s, want number", typeName(args[0]))
+		}
+		return f(x), nil
+	}
+}
+
+func fold(f func(a, b float64) float64) func([]any) (any, error) {
+	return func(args []any) (any, error) {
+		var result float64
+		for i, arg := range args {
+			x, ok := arg.(float64)
+			if !ok {
+				return nil, fmt.Errorf("argument %d is %s, want number", i+1, typeName(arg))
+			}
+			if i == 0 {
+				result = x
+			} else {
+				result = f(result, x)
+			}
+		}
+		return result, nil
+	}
+}
+
+func text(f func(string) string) func([]any) (any, error) {
+	return func(args []any) (any, error) {
+		s, ok := args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("argument is %s, want string", typeName(args[0]))
+		}
+		return f(s), nil
+	}
+}
+
+func stringPredicate(f func(s, sub string) bool) func([]any) (any, error) {
+	return func(args []any) (any, error) {
+		s, ok1 := args[0].(string)
+		sub, ok2 := args[1].(string)
+		if !ok1 || !ok2 {
+			return nil, fmt.Errorf("arguments are %s and %s, want strings", typeName(args[0]), typeName(args[1]))
+		}
+		return f(s, sub), nil
+	}
+}
+
+func length(args []any) (any, error) {
+	switch v := args[0].(type) {
+	case string:
+		return float64(len(v)), nil
+	case []any:
+		return float64(len(v)), nil
+	}
+	return nil, fmt.Errorf("argument is %s, want string or list", typeName(args[0]))
+}
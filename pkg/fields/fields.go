@@ -0,0 +1,36 @@
+// Package fields resolves dotted field paths, such as rds.ps or
+// fm_info.DeviationMax, in values decoded from JSON
+package fields
+
+import "strings"
+
+// Normalize folds case and underscores so that "rds.long_ps",
+// "RDS.LongPS" and "rds.longps" name the same field
+func Normalize(path string) string {
+	return strings.ReplaceAll(strings.ToLower(path), "_", "")
+}
+
+// Lookup follows a dotted path through decoded JSON objects. A key
+// matches exactly or else after Normalize.
+func Lookup(v any, path string) (any, bool) {
+	for _, key := range strings.Split(path, ".") {
+		object, ok := v.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if v, ok = object[key]; ok {
+			continue
+		}
+		key = Normalize(key)
+		for k, value := range object {
+			if Normalize(k) == key {
+				v, ok = value, true
+				break
+			}
+		}
+		if !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
@@ -0,0 +1,30 @@
+package fields
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	var object any
+	json.Unmarshal([]byte(`{"DeviationMax":75000,"RDS":{"ps":"RADIO 1 ","long_ps":"Radio One"}}`), &object)
+	tests := []struct {
+		path string
+		want any
+		ok   bool
+	}{
+		{"DeviationMax", 75000.0, true},
+		{"deviation_max", 75000.0, true},
+		{"rds.ps", "RADIO 1 ", true},
+		{"RDS.LongPS", "Radio One", true},
+		{"rds.long_ps", "Radio One", true},
+		{"rds.rt", nil, false},
+		{"deviationmax.value", nil, false},
+	}
+	for _, tt := range tests {
+		got, ok := Lookup(object, tt.path)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("Lookup(%s) = %v, %v, want %v, %v", tt.path, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+// Package influx renders analyzer readings as InfluxDB line protocol
+package influx
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Tag is a point tag
+type Tag struct {
+	Key   string
+	Value string
+}
+
+// Field is a point field, Value is float64, int64, uint64, string or bool
+type Field struct {
+	Key   string
+	Value any
+}
+
+// Point is a line protocol point
+type Point struct {
+	Measurement string
+	Tags        []Tag
+	Fields      []Field
+	Time        time.Time
+}
+
+// Line breaks end a line whatever the escaping, they are replaced by
+// spaces, e.g. the line break RDS RT may carry
+var (
+	measurementEscaper = strings.NewReplacer(",", `\,`, " ", `\ `, "\n", `\ `, "\r", `\ `)
+	keyEscaper         = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `, "\n", `\ `, "\r", `\ `)
+	stringEscaper      = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", " ", "\r", " ")
+)
+
+// AppendLine appends the point as a line with nanosecond precision. Tags
+// are sorted by key and empty tag values are left out, as line protocol
+// does not allow them. NaN and infinite floats, e.g. the modulation power
+// of silence, cannot be written either and are left out.
+func (p *Point) AppendLine(buf []byte) ([]byte, error) {
+	if p.Measurement == "" || len(p.Fields) == 0 {
+		return buf, fmt.Errorf("point %q without measurement or fields", p.Measurement)
+	}
+	buf = append(buf, measurementEscaper.Replace(p.Measurement)...)
+	tags := slices.Clone(p.Tags)
+	slices.SortFunc(tags, func(a, b Tag) int { return strings.Compare(a.Key, b.Key) })
+	for _, t := range tags {
+		if t.Value == "" {
+			continue
+		}
+		buf = append(buf, ',')
+		buf = append(buf, keyEscaper.Replace(t.Key)...)
+		buf = append(buf, '=')
+		buf = append(buf, keyEscaper.Replace(t.Value)...)
+	}
+	fields := 0
+	for _, f := range p.Fields {
+		if v, ok := f.Value.(float64); ok && (math.IsNaN(v) || math.IsInf(v, 0)) {
+			continue
+		}
+		if fields == 0 {
+			buf = append(buf, ' ')
+		} else {
+			buf = append(buf, ',')
+		}
+		fields++
+		buf = append(buf, keyEscaper.Replace(f.Key)...)
+		buf = append(buf, '=')
+		switch v := f.Value.(type) {
+		case float64:
+			buf = strconv.AppendFloat(buf, v, 'f', -1, 64)
+		case int64:
+			buf = strconv.AppendInt(buf, v, 10)
+			buf = append(buf, 'i')
+		case uint64:
+			buf = strconv.AppendUint(buf, v, 10)
+			buf = append(buf, 'u')
+		case string:
+			buf = append(buf, '"')
+			buf = append(buf, stringEscaper.Replace(v)...)
+			buf = append(buf, '"')
+		case bool:
+			buf = strconv.AppendBool(buf, v)
+		default:
+			return buf, fmt.Errorf("field %s: unsupported type %T", f.Key, f.Value)
+		}
+	}
+	if fields == 0 {
+		return buf, fmt.Errorf("point %q without finite fields", p.Measurement)
+	}
+	if !p.Time.IsZero() {
+		buf = append(buf, ' ')
+		buf = strconv.AppendInt(buf, p.Time.UnixNano(), 10)
+	}
+	return append(buf, '\n'), nil
+}
+
+// String returns the line of the point without the newline
+func (p *Point) String() string {
+	line, err := p.AppendLine(nil)
+	if err != nil {
+		return err.Error()
+	}
+	return strings.TrimSuffix(string(line), "\n")
+}
+
+// Encode renders points as line protocol
+func Encode(points []Point) ([]byte, error) {
+	var buf []byte
+	for i := range points {
+		var err error
+		if buf, err = points[i].AppendLine(buf); err != nil {
+			return nil, err
+		}
+	}
+	return buf, nil
+}
@@ -0,0 +1,64 @@
+package influx
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestPoint_String(t *testing.T) {
+	at := time.Unix(1, 5)
+	tests := []struct {
+		name  string
+		point Point
+		want  string
+	}{
+		{
+			name:  "types",
+			point: Point{Measurement: "m", Fields: []Field{{"f", 1.5}, {"i", int64(-2)}, {"u", uint64(3)}, {"s", "a"}, {"b", true}}, Time: at},
+			want:  `m f=1.5,i=-2i,u=3u,s="a",b=true 1000000005`,
+		},
+		{
+			name:  "sorted tags without empty values",
+			point: Point{Measurement: "m", Tags: []Tag{{"z", "1"}, {"a", "2"}, {"e", ""}}, Fields: []Field{{"f", 1.0}}},
+			want:  `m,a=2,z=1 f=1`,
+		},
+		{
+			name:  "escaping",
+			point: Point{Measurement: "a b,c", Tags: []Tag{{"k=1", "v w,x"}}, Fields: []Field{{"s", `say "hi" \o/`}}},
+			want:  `a\ b\,c,k\=1=v\ w\,x s="say \"hi\" \\o/"`,
+		},
+		{
+			name:  "line breaks",
+			point: Point{Measurement: "m", Tags: []Tag{{"ps", "A\nB"}}, Fields: []Field{{"rt", "Artist\nTitle\r"}}},
+			want:  `m,ps=A\ B rt="Artist Title "`,
+		},
+		{
+			name:  "non-finite floats",
+			point: Point{Measurement: "m", Fields: []Field{{"power", math.Inf(-1)}, {"nan", math.NaN()}, {"quality", int64(5)}}},
+			want:  `m quality=5i`,
+		},
+		{
+			name:  "only non-finite floats",
+			point: Point{Measurement: "m", Fields: []Field{{"power", math.Inf(-1)}}},
+			want:  `point "m" without finite fields`,
+		},
+		{
+			name:  "no fields",
+			point: Point{Measurement: "m"},
+			want:  `point "m" without measurement or fields`,
+		},
+		{
+			name:  "unsupported type",
+			point: Point{Measurement: "m", Fields: []Field{{"f", 1}}},
+			want:  `field f: unsupported type int`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.point.String(); got != tt.want {
+				t.Errorf("String() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package influx
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"go-pira/pkg/pira"
+)
+
+// Measurements written for a snapshot
+const (
+	MeasurementDeviation = "pira_deviation"
+	MeasurementSignal    = "pira_signal"
+	MeasurementRDS       = "pira_rds"
+	MeasurementGroups    = "pira_rds_groups"
+	MeasurementBasic     = "pira_basic"
+)
+
+// Points converts a snapshot into one point per data group. Points are
+// tagged with device, frequency and PI besides tags. Failed reads have
+// no points.
+func Points(s pira.Snapshot, device string, tags ...Tag) []Point {
+	if s.Err != nil {
+		return nil
+	}
+	var points []Point
+	if fmi := s.FMInfo; fmi != nil {
+		common := append(tags[:len(tags):len(tags)],
+			Tag{"device", device},
+			Tag{"frequency", formatUint(fmi.Frequency)},
+			Tag{"pi", fmi.RDS.PI.String()},
+		)
+		deviation := Point{Measurement: MeasurementDeviation, Tags: common, Time: s.Time, Fields: []Field{
+			{"current", uint64(fmi.Deviation)},
+			{"max", uint64(fmi.DeviationMax)},
+			{"average", uint64(fmi.DeviationAverage)},
+			{"min_hold", uint64(fmi.DeviationMinHold)},
+			{"pilot", uint64(fmi.PilotDeviation)},
+			{"rds", uint64(fmi.RDSDeviation)},
+		}}
+		// fast snapshots only refresh the deviations, without the max hold,
+		// the other fields are copies of the last slow read
+		slow := s.Kind == pira.SnapshotSlow
+		if slow {
+			deviation.Fields = append(deviation.Fields, Field{"max_hold", uint64(fmi.DeviationMaxHold)})
+		}
+		points = append(points, deviation)
+		if slow {
+			rds := &fmi.RDS
+			points = append(points, Point{Measurement: MeasurementSignal, Tags: common, Time: s.Time, Fields: []Field{
+				{"quality", int64(fmi.SignalQuality)},
+				{"noise_level", uint64(fmi.NoiseLevel)},
+				{"am", uint64(fmi.AM)},
+				{"modulation_power", fmi.ModulationPower},
+			}})
+			points = append(points, Point{Measurement: MeasurementRDS, Tags: common, Time: s.Time, Fields: []Field{
+				{"phase_difference", int64(fmi.RDSPhaseDifference)},
+				{"ps", rds.PS},
+				{"rt", strings.TrimRight(rds.RT, "\r\x00 ")},
+				{"pty", int64(rds.PTY.Code)},
+				{"ta", rds.Status.TA},
+				{"tp", rds.Status.TP},
+				{"ms", rds.Status.MS},
+			}})
+			groups := Point{Measurement: MeasurementGroups, Tags: common, Time: s.Time}
+			for i, c := range rds.Groups {
+				groups.Fields = append(groups.Fields, Field{"g" + pira.RDSGroupName(i), uint64(c)})
+			}
+			points = append(points, groups)
+		}
+	}
+	if bd := s.BasicData; bd != nil {
+		basic := Point{Measurement: MeasurementBasic, Tags: append(tags[:len(tags):len(tags)], Tag{"device", device}), Time: s.Time}
+		for name, value := range bd.Metrics() {
+			basic.Fields = append(basic.Fields, Field{name, value})
+		}
+		sortFields(basic.Fields)
+		points = append(points, basic)
+	}
+	return points
+}
+
+func formatUint(v uint32) string {
+	return strconv.FormatUint(uint64(v), 10)
+}
+
+// sortFields orders fields built from a map by key
+func sortFields(fields []Field) {
+	slices.SortFunc(fields, func(a, b Field) int { return strings.Compare(a.Key, b.Key) })
+}
@@ -0,0 +1,44 @@
+package influx
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go-pira/pkg/pira"
+)
+
+func TestPoints(t *testing.T) {
+	at := time.Unix(100, 0)
+	fmi := &pira.FMInfo{Frequency: 98500, DeviationMax: 75000, RDS: pira.RDSInfo{PI: 0xD3C2, PS: "RADIO 1 "}}
+	fmi.RDS.Groups[0] = 10
+
+	points := Points(pira.Snapshot{Time: at, FMInfo: fmi}, "site1", Tag{"station", "r1"})
+	var measurements []string
+	for _, p := range points {
+		measurements = append(measurements, p.Measurement)
+	}
+	want := []string{MeasurementDeviation, MeasurementSignal, MeasurementRDS, MeasurementGroups}
+	if strings.Join(measurements, " ") != strings.Join(want, " ") {
+		t.Fatalf("measurements = %v, want %v", measurements, want)
+	}
+	line := points[0].String()
+	if !strings.HasPrefix(line, "pira_deviation,device=site1,frequency=98500,pi=D3C2,station=r1 current=0u,max=75000u,") {
+		t.Errorf("deviation line = %s", line)
+	}
+	if line := points[3].String(); !strings.Contains(line, " g0A=10u,g0B=0u,") {
+		t.Errorf("groups line = %s", line)
+	}
+
+	fast := Points(pira.Snapshot{Time: at, Kind: pira.SnapshotFast, FMInfo: fmi}, "site1")
+	if len(fast) != 1 || fast[0].Measurement != MeasurementDeviation {
+		t.Fatalf("fast snapshot points = %v, want deviation only", fast)
+	}
+	if line := fast[0].String(); strings.Contains(line, "max_hold") {
+		t.Errorf("fast deviation line = %s, want no max hold", line)
+	}
+	if failed := Points(pira.Snapshot{Time: at, Err: errors.New("timeout")}, "site1"); len(failed) != 0 {
+		t.Errorf("failed snapshot points = %d, want 0", len(failed))
+	}
+}
@@ -0,0 +1,166 @@
+package influx
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Writer writes points to a destination
+type Writer interface {
+	WritePoints(ctx context.Context, points []Point) error
+	Close() error
+}
+
+// LineWriter writes line protocol to an io.Writer, a file or stdout
+type LineWriter struct {
+	w io.Writer
+}
+
+// NewLineWriter returns a writer to w, it is closed with the writer when
+// w is an io.Closer
+func NewLineWriter(w io.Writer) *LineWriter {
+	return &LineWriter{w: w}
+}
+
+// WritePoints implements the Writer interface for LineWriter
+func (w *LineWriter) WritePoints(ctx context.Context, points []Point) error {
+	data, err := Encode(points)
+	if err != nil {
+		return err
+	}
+	_, err = w.w.Write(data)
+	return err
+}
+
+// Close implements the Writer interface for LineWriter
+func (w *LineWriter) Close() error {
+	if c, ok := w.w.(io.Closer); ok && w.w != os.Stdout {
+		return c.Close()
+	}
+	return nil
+}
+
+// maxDatagram is the payload size of UDP writes, lines are never split
+const maxDatagram = 1400
+
+// UDPWriter sends line protocol datagrams to the InfluxDB UDP listener
+type UDPWriter struct {
+	conn net.Conn
+}
+
+// DialUDP returns a writer sending to addr
+func DialUDP(addr string) (*UDPWriter, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &UDPWriter{conn: conn}, nil
+}
+
+// WritePoints implements the Writer interface for UDPWriter, points are
+// packed into datagrams of up to maxDatagram bytes
+func (w *UDPWriter) WritePoints(ctx context.Context, points []Point) error {
+	var datagram []byte
+	for i := range points {
+		line, err := points[i].AppendLine(nil)
+		if err != nil {
+			return err
+		}
+		if len(datagram) > 0 && len(datagram)+len(line) > maxDatagram {
+			if _, err := w.conn.Write(datagram); err != nil {
+				return err
+			}
+			datagram = datagram[:0]
+		}
+		datagram = append(datagram, line...)
+	}
+	if len(datagram) > 0 {
+		_, err := w.conn.Write(datagram)
+		return err
+	}
+	return nil
+}
+
+// Close implements the Writer interface for UDPWriter
+func (w *UDPWriter) Close() error {
+	return w.conn.Close()
+}
+
+// HTTPWriter posts line protocol to a write endpoint, the v1 /write?db=
+// or the v2 /api/v2/write?org=&bucket= URL with precision ns, the default
+type HTTPWriter struct {
+	URL string
+	// Token is sent as "Authorization: Token <token>" when set
+	Token  string
+	Client *http.Client
+}
+
+// WritePoints implements the Writer interface for HTTPWriter
+func (w *HTTPWriter) WritePoints(ctx context.Context, points []Point) error {
+	if len(points) == 0 {
+		return nil
+	}
+	data, err := Encode(points)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if w.Token != "" {
+		req.Header.Set("Authorization", "Token "+w.Token)
+	}
+	client := w.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to write points: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("failed to write points: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// Close implements the Writer interface for HTTPWriter
+func (w *HTTPWriter) Close() error {
+	return nil
+}
+
+// Open returns the writer for target: "-" for stdout, udp://host:port,
+// an http:// or https:// write URL, or a file path that is appended to
+func Open(target, token string) (Writer, error) {
+	if target == "-" {
+		return NewLineWriter(os.Stdout), nil
+	}
+	u, err := url.Parse(target)
+	if err == nil {
+		switch u.Scheme {
+		case "udp":
+			return DialUDP(u.Host)
+		case "http", "https":
+			return &HTTPWriter{URL: target, Token: token}, nil
+		case "file":
+			target = u.Path
+		}
+	}
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return NewLineWriter(f), nil
+}
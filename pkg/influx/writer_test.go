@@ -0,0 +1,78 @@
+package influx
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var testPoints = []Point{
+	{Measurement: "m", Tags: []Tag{{"device", "d"}}, Fields: []Field{{"v", 1.0}}},
+	{Measurement: "m", Tags: []Tag{{"device", "d"}}, Fields: []Field{{"v", 2.0}}},
+}
+
+func TestHTTPWriter(t *testing.T) {
+	var body, auth string
+	status := http.StatusNoContent
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body, auth = string(data), r.Header.Get("Authorization")
+		w.WriteHeader(status)
+		if status != http.StatusNoContent {
+			io.WriteString(w, `{"error":"bad line"}`)
+		}
+	}))
+	defer ts.Close()
+
+	w := &HTTPWriter{URL: ts.URL + "/api/v2/write?org=o&bucket=b", Token: "secret"}
+	if err := w.WritePoints(context.Background(), testPoints); err != nil {
+		t.Fatalf("WritePoints() error = %v", err)
+	}
+	if body != "m,device=d v=1\nm,device=d v=2\n" || auth != "Token secret" {
+		t.Errorf("request body = %q, Authorization = %q", body, auth)
+	}
+
+	status = http.StatusBadRequest
+	err := w.WritePoints(context.Background(), testPoints)
+	if err == nil || !strings.Contains(err.Error(), "400 Bad Request: {\"error\":\"bad line\"}") {
+		t.Errorf("WritePoints() error = %v", err)
+	}
+}
+
+func TestUDPWriter(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	w, err := Open("udp://"+conn.LocalAddr().String(), "")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer w.Close()
+
+	// enough points to need two datagrams
+	points := make([]Point, 100)
+	for i := range points {
+		points[i] = testPoints[0]
+	}
+	if err := w.WritePoints(context.Background(), points); err != nil {
+		t.Fatalf("WritePoints() error = %v", err)
+	}
+	var lines int
+	buf := make([]byte, 2*maxDatagram)
+	for lines < len(points) {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n > maxDatagram {
+			t.Errorf("datagram size = %d, want <= %d", n, maxDatagram)
+		}
+		lines += strings.Count(string(buf[:n]), "\n")
+	}
+}
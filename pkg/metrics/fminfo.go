@@ -0,0 +1,156 @@
+package metrics
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"go-pira/pkg/pira"
+)
+
+// gauge describes an FMInfo field exported as a gauge
+type gauge struct {
+	name  string
+	help  string
+	value func(fmi *pira.FMInfo) float64
+}
+
+var fmInfoGauges = []gauge{
+	{"pira_frequency_hertz", "Tuned frequency.", func(f *pira.FMInfo) float64 { return float64(f.Frequency) * pira.FrequencyStep }},
+	{"pira_pilot_deviation_hertz", "Pilot deviation.", func(f *pira.FMInfo) float64 { return float64(f.PilotDeviation) }},
+	{"pira_rds_deviation_hertz", "RDS deviation.", func(f *pira.FMInfo) float64 { return float64(f.RDSDeviation) }},
+	{"pira_rds_phase_difference", "Pilot to RDS phase difference.", func(f *pira.FMInfo) float64 { return float64(f.RDSPhaseDifference) }},
+	{"pira_deviation_hertz", "Current deviation.", func(f *pira.FMInfo) float64 { return float64(f.Deviation) }},
+	{"pira_deviation_max_hertz", "Peak deviation.", func(f *pira.FMInfo) float64 { return float64(f.DeviationMax) }},
+	{"pira_deviation_average_hertz", "Average deviation.", func(f *pira.FMInfo) float64 { return float64(f.DeviationAverage) }},
+	{"pira_deviation_min_hold_hertz", "Minimum hold deviation.", func(f *pira.FMInfo) float64 { return float64(f.DeviationMinHold) }},
+	{"pira_deviation_max_hold_hertz", "Maximum hold deviation.", func(f *pira.FMInfo) float64 { return float64(f.DeviationMaxHold) }},
+	{"pira_modulation_power_dbr", "Modulation power.", func(f *pira.FMInfo) float64 { return f.ModulationPower }},
+	{"pira_signal_quality", "Signal quality.", func(f *pira.FMInfo) float64 { return float64(f.SignalQuality) }},
+	{"pira_noise_level", "Noise level.", func(f *pira.FMInfo) float64 { return float64(f.NoiseLevel) }},
+	{"pira_am", "AM level.", func(f *pira.FMInfo) float64 { return float64(f.AM) }},
+}
+
+// FMInfoCollector exports the last FMInfo of a monitor. Samples are
+// labeled with the device and the station, which defaults to the PI code
+// when not set.
+type FMInfoCollector struct {
+	Device  string
+	Station string
+
+	mu     sync.Mutex
+	fmi    *pira.FMInfo
+	at     time.Time
+	groups *pira.RDSGroupStats
+	up     bool
+}
+
+// Update stores a monitor snapshot, group rates are computed between
+// successive slow snapshots
+func (c *FMInfoCollector) Update(s pira.Snapshot) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.up = s.Err == nil
+	if s.Err != nil {
+		return
+	}
+	fmi := *s.FMInfo
+	c.fmi, c.at = &fmi, s.Time
+	if s.Kind == pira.SnapshotSlow {
+		c.groups = fmi.RDS.GroupStats(s.Time, c.groups)
+	}
+}
+
+func (c *FMInfoCollector) labels() []Label {
+	station := c.Station
+	if station == "" && c.fmi != nil {
+		station = c.fmi.RDS.PI.String()
+	}
+	return []Label{{"device", c.Device}, {"station", station}}
+}
+
+// Families implements the Collector interface
+func (c *FMInfoCollector) Families() []Family {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	labels := c.labels()
+	up := 0.0
+	if c.up {
+		up = 1
+	}
+	families := []Family{{
+		Name:    "pira_up",
+		Help:    "Whether the last analyzer read succeeded.",
+		Type:    TypeGauge,
+		Samples: []Sample{{Name: "pira_up", Labels: labels, Value: up}},
+	}}
+	if c.fmi == nil {
+		return families
+	}
+
+	families = append(families, Family{
+		Name:    "pira_last_read_timestamp_seconds",
+		Help:    "Time of the last successful analyzer read.",
+		Type:    TypeGauge,
+		Samples: []Sample{{Name: "pira_last_read_timestamp_seconds", Labels: labels, Value: float64(c.at.UnixNano()) / 1e9}},
+	})
+	for _, g := range fmInfoGauges {
+		families = append(families, Family{
+			Name:    g.name,
+			Help:    g.help,
+			Type:    TypeGauge,
+			Samples: []Sample{{Name: g.name, Labels: labels, Value: g.value(c.fmi)}},
+		})
+	}
+
+	rds := &c.fmi.RDS
+	families = append(families, Family{
+		Name: "pira_rds_info",
+		Help: "RDS service information, always 1.",
+		Type: TypeGauge,
+		Samples: []Sample{{
+			Name: "pira_rds_info",
+			Labels: append(labels[:len(labels):len(labels)],
+				Label{"pi", rds.PI.String()},
+				Label{"ps", strings.TrimSpace(rds.PS)},
+				Label{"pty", rds.PTY.String()},
+			),
+			Value: 1,
+		}},
+	})
+
+	if c.groups != nil && c.groups.Interval > 0 {
+		rates := Family{Name: "pira_rds_group_rate", Help: "RDS groups received per second by group type.", Type: TypeGauge}
+		for _, g := range c.groups.Groups {
+			rates.Samples = append(rates.Samples, Sample{Name: rates.Name, Labels: withLabel(labels, Label{"group", g.Group}), Value: g.Rate})
+		}
+		families = append(families, rates, Family{
+			Name:    "pira_rds_groups_per_second",
+			Help:    "RDS groups received per second.",
+			Type:    TypeGauge,
+			Samples: []Sample{{Name: "pira_rds_groups_per_second", Labels: labels, Value: c.groups.GroupsPerSecond}},
+		})
+	}
+
+	families = append(families, deviationHistogram(labels, c.fmi.Histogram))
+	return families
+}
+
+// deviationHistogram exports the analyzer histogram as a gauge. The
+// histogram is a rolling snapshot whose bins also go down, so it is not a
+// Prometheus histogram. Bin i is taken to count the deviation samples
+// between i and i+1 kHz, the samples are cumulative by the le upper bound
+// so histogram_quantile applies to them without rate.
+func deviationHistogram(labels []Label, histogram [122]uint16) Family {
+	const name = "pira_deviation_histogram_samples"
+	f := Family{Name: name, Help: "Deviation samples of the analyzer histogram at or below le.", Type: TypeGauge}
+	var cumulative float64
+	for i, c := range histogram {
+		cumulative += float64(c)
+		le := formatValue(float64(i+1) * 1000)
+		f.Samples = append(f.Samples, Sample{Name: name, Labels: withLabel(labels, Label{"le", le}), Value: cumulative})
+	}
+	f.Samples = append(f.Samples, Sample{Name: name, Labels: withLabel(labels, Label{"le", "+Inf"}), Value: cumulative})
+	return f
+}
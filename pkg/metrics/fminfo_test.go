@@ -0,0 +1,99 @@
+package metrics
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-pira/pkg/pira"
+)
+
+type fakeSource struct {
+	err error
+}
+
+func (f *fakeSource) GetFMInfo(fmi *pira.FMInfo) error {
+	fmi.DeviationMax = 75000
+	fmi.RDS.PI = 0x5201
+	return f.err
+}
+
+func (f *fakeSource) GetDeviations(fmi *pira.FMInfo) error {
+	return f.err
+}
+
+func (f *fakeSource) GetBasicData() (*pira.BasicData, error) {
+	return &pira.BasicData{}, f.err
+}
+
+func TestFMInfoCollector(t *testing.T) {
+	c := &FMInfoCollector{Device: "pira1"}
+	start := time.Unix(1700000000, 0)
+
+	fmi := pira.FMInfo{Frequency: 100500, DeviationMax: 75000, SignalQuality: 88, RDS: pira.RDSInfo{PI: 0x5201, PS: "RADIO 1 "}}
+	fmi.Histogram[0] = 4
+	fmi.Histogram[2] = 1
+	c.Update(pira.Snapshot{Time: start, Kind: pira.SnapshotSlow, FMInfo: &fmi})
+	fmi.RDS.Groups[0] = 20
+	c.Update(pira.Snapshot{Time: start.Add(2 * time.Second), Kind: pira.SnapshotSlow, FMInfo: &fmi})
+
+	rec := httptest.NewRecorder()
+	Handler(c).ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		`pira_up{device="pira1",station="5201"} 1`,
+		`pira_frequency_hertz{device="pira1",station="5201"} 1.005e+08`,
+		`pira_deviation_max_hertz{device="pira1",station="5201"} 75000`,
+		`pira_signal_quality{device="pira1",station="5201"} 88`,
+		`pira_rds_info{device="pira1",station="5201",pi="5201",ps="RADIO 1",pty="None"} 1`,
+		`pira_rds_group_rate{device="pira1",station="5201",group="0A"} 10`,
+		`pira_rds_groups_per_second{device="pira1",station="5201"} 10`,
+		`# TYPE pira_deviation_histogram_samples gauge`,
+		`pira_deviation_histogram_samples{device="pira1",station="5201",le="1000"} 4`,
+		`pira_deviation_histogram_samples{device="pira1",station="5201",le="3000"} 5`,
+		`pira_deviation_histogram_samples{device="pira1",station="5201",le="+Inf"} 5`,
+	} {
+		if !strings.Contains(body, want+"\n") {
+			t.Errorf("metrics missing %s", want)
+		}
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+
+	c.Update(pira.Snapshot{Err: errors.New("timeout")})
+	var b strings.Builder
+	Write(&b, c.Families())
+	if !strings.Contains(b.String(), `pira_up{device="pira1",station="5201"} 0`) {
+		t.Errorf("pira_up after error:\n%s", b.String())
+	}
+}
+
+func TestInstrumentedSource(t *testing.T) {
+	src := &fakeSource{}
+	s := NewInstrumentedSource(src, Label{"device", "pira1"})
+	var fmi pira.FMInfo
+	s.GetFMInfo(&fmi)
+	src.err = errors.New("timeout")
+	s.GetFMInfo(&fmi)
+	s.GetDeviations(&fmi)
+
+	var b strings.Builder
+	Write(&b, s.Families())
+	for _, want := range []string{
+		`pira_source_reads_total{device="pira1",station="5201",op="fminfo"} 2`,
+		`pira_source_errors_total{device="pira1",station="5201",op="fminfo"} 1`,
+		`pira_source_reads_total{device="pira1",station="5201",op="deviations"} 1`,
+		`pira_source_read_duration_seconds_count{device="pira1",station="5201",op="fminfo"} 2`,
+		`pira_source_read_duration_seconds_bucket{device="pira1",station="5201",op="fminfo",le="+Inf"} 2`,
+	} {
+		if !strings.Contains(b.String(), want+"\n") {
+			t.Errorf("metrics missing %s", want)
+		}
+	}
+	if fmi.DeviationMax != 75000 {
+		t.Errorf("GetFMInfo() did not reach the source")
+	}
+}
@@ -0,0 +1,120 @@
+package metrics
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"go-pira/pkg/pira"
+)
+
+// latencyBuckets are the upper bounds in seconds of the read latency histogram
+var latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5}
+
+type opStats struct {
+	reads, errors uint64
+	buckets       []uint64
+	sum           float64
+}
+
+// opKey identifies the stats of an operation on a station
+type opKey struct {
+	station, op string
+}
+
+// InstrumentedSource counts the reads, errors and latency of a pira.Source.
+// Samples are labeled with the station, which defaults to the PI code of
+// the last FMInfo read when not set.
+type InstrumentedSource struct {
+	Station string
+
+	src    pira.Source
+	labels []Label
+
+	mu  sync.Mutex
+	pi  string
+	ops map[opKey]*opStats
+}
+
+// NewInstrumentedSource wraps src, labels are added to every sample
+func NewInstrumentedSource(src pira.Source, labels ...Label) *InstrumentedSource {
+	return &InstrumentedSource{src: src, labels: labels, ops: make(map[opKey]*opStats)}
+}
+
+// GetFMInfo implements the pira.Source interface
+func (s *InstrumentedSource) GetFMInfo(fmi *pira.FMInfo) error {
+	start := time.Now()
+	err := s.src.GetFMInfo(fmi)
+	s.observe("fminfo", start, err, fmi)
+	return err
+}
+
+// GetDeviations implements the pira.Source interface
+func (s *InstrumentedSource) GetDeviations(fmi *pira.FMInfo) error {
+	start := time.Now()
+	err := s.src.GetDeviations(fmi)
+	s.observe("deviations", start, err, nil)
+	return err
+}
+
+// GetBasicData implements the pira.Source interface
+func (s *InstrumentedSource) GetBasicData() (*pira.BasicData, error) {
+	start := time.Now()
+	bd, err := s.src.GetBasicData()
+	s.observe("basic_data", start, err, nil)
+	return bd, err
+}
+
+// observe records a read, fmi updates the PI code of the station label
+// when the read succeeded
+func (s *InstrumentedSource) observe(op string, start time.Time, err error, fmi *pira.FMInfo) {
+	seconds := time.Since(start).Seconds()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if fmi != nil && err == nil {
+		s.pi = fmi.RDS.PI.String()
+	}
+	key := opKey{s.Station, op}
+	if key.station == "" {
+		key.station = s.pi
+	}
+	stats, ok := s.ops[key]
+	if !ok {
+		stats = &opStats{buckets: make([]uint64, len(latencyBuckets)+1)}
+		s.ops[key] = stats
+	}
+	stats.reads++
+	if err != nil {
+		stats.errors++
+	}
+	stats.buckets[sort.SearchFloat64s(latencyBuckets, seconds)]++
+	stats.sum += seconds
+}
+
+// Families implements the Collector interface
+func (s *InstrumentedSource) Families() []Family {
+	reads := Family{Name: "pira_source_reads_total", Help: "Reads from the analyzer.", Type: TypeCounter}
+	errors := Family{Name: "pira_source_errors_total", Help: "Failed reads from the analyzer.", Type: TypeCounter}
+	latency := Family{Name: "pira_source_read_duration_seconds", Help: "Analyzer read latency.", Type: TypeHistogram}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]opKey, 0, len(s.ops))
+	for key := range s.ops {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].station != keys[j].station {
+			return keys[i].station < keys[j].station
+		}
+		return keys[i].op < keys[j].op
+	})
+	for _, key := range keys {
+		stats := s.ops[key]
+		labels := withLabel(withLabel(s.labels, Label{"station", key.station}), Label{"op", key.op})
+		reads.Samples = append(reads.Samples, Sample{Name: reads.Name, Labels: labels, Value: float64(stats.reads)})
+		errors.Samples = append(errors.Samples, Sample{Name: errors.Name, Labels: labels, Value: float64(stats.errors)})
+		latency.Samples = append(latency.Samples, histogramSamples(latency.Name, labels, latencyBuckets, stats.buckets, stats.sum)...)
+	}
+	return []Family{reads, errors, latency}
+}
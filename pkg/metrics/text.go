@@ -0,0 +1,142 @@
+// Package metrics exposes analyzer readings in the Prometheus text format
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Type is the metric family type
+type Type string
+
+const (
+	TypeGauge     Type = "gauge"
+	TypeCounter   Type = "counter"
+	TypeHistogram Type = "histogram"
+)
+
+// Label is a metric label
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Sample is a single value of a family. Name is the family name, with the
+// _bucket, _sum or _count suffix for histograms.
+type Sample struct {
+	Name   string
+	Labels []Label
+	Value  float64
+}
+
+// Family is a named group of samples
+type Family struct {
+	Name    string
+	Help    string
+	Type    Type
+	Samples []Sample
+}
+
+// Collector returns the current metric families
+type Collector interface {
+	Families() []Family
+}
+
+// Write writes families in the Prometheus text exposition format 0.0.4
+func Write(w io.Writer, families []Family) error {
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		if len(f.Samples) == 0 {
+			continue
+		}
+		bw.WriteString("# HELP " + f.Name + " " + escapeHelp(f.Help) + "\n")
+		bw.WriteString("# TYPE " + f.Name + " " + string(f.Type) + "\n")
+		for _, s := range f.Samples {
+			bw.WriteString(s.Name)
+			if len(s.Labels) > 0 {
+				bw.WriteByte('{')
+				for i, l := range s.Labels {
+					if i > 0 {
+						bw.WriteByte(',')
+					}
+					bw.WriteString(l.Name + `="` + escapeLabel(l.Value) + `"`)
+				}
+				bw.WriteByte('}')
+			}
+			bw.WriteString(" " + formatValue(s.Value) + "\n")
+		}
+	}
+	return bw.Flush()
+}
+
+// Handler serves the families of the collectors, sorted by name
+func Handler(collectors ...Collector) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var families []Family
+		for _, c := range collectors {
+			families = append(families, c.Families()...)
+		}
+		sort.SliceStable(families, func(i, j int) bool { return families[i].Name < families[j].Name })
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		Write(w, families)
+	})
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+// withLabel returns a copy of labels with l appended
+func withLabel(labels []Label, l Label) []Label {
+	return append(labels[:len(labels):len(labels)], l)
+}
+
+// histogramSamples returns the cumulative bucket, sum and count samples of
+// a histogram, counts are per bucket with upper bounds bounds and the last
+// count is the +Inf bucket
+func histogramSamples(name string, labels []Label, bounds []float64, counts []uint64, sum float64) []Sample {
+	samples := make([]Sample, 0, len(counts)+2)
+	var cumulative uint64
+	for i, c := range counts {
+		cumulative += c
+		le := "+Inf"
+		if i < len(bounds) {
+			le = formatValue(bounds[i])
+		}
+		samples = append(samples, Sample{
+			Name:   name + "_bucket",
+			Labels: withLabel(labels, Label{"le", le}),
+			Value:  float64(cumulative),
+		})
+	}
+	return append(samples,
+		Sample{Name: name + "_sum", Labels: labels, Value: sum},
+		Sample{Name: name + "_count", Labels: labels, Value: float64(cumulative)},
+	)
+}
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	families := []Family{
+		{
+			Name: "pira_signal_quality",
+			Help: "Signal\nquality.",
+			Type: TypeGauge,
+			Samples: []Sample{
+				{Name: "pira_signal_quality", Labels: []Label{{"device", "a"}, {"station", `R"1\`}}, Value: 90},
+			},
+		},
+		{Name: "pira_empty", Help: "Skipped.", Type: TypeGauge},
+		{
+			Name:    "pira_nan",
+			Help:    "Special values.",
+			Type:    TypeGauge,
+			Samples: []Sample{{Name: "pira_nan", Value: math.NaN()}, {Name: "pira_nan", Value: math.Inf(1)}, {Name: "pira_nan", Value: 0.25}},
+		},
+	}
+	var b strings.Builder
+	if err := Write(&b, families); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	want := `# HELP pira_signal_quality Signal\nquality.
+# TYPE pira_signal_quality gauge
+pira_signal_quality{device="a",station="R\"1\\"} 90
+# HELP pira_nan Special values.
+# TYPE pira_nan gauge
+pira_nan NaN
+pira_nan +Inf
+pira_nan 0.25
+`
+	if b.String() != want {
+		t.Errorf("Write() =\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestHistogramSamples(t *testing.T) {
+	labels := []Label{{"device", "a"}}
+	samples := histogramSamples("h", labels, []float64{1, 2}, []uint64{3, 0, 2}, 7.5)
+	var b strings.Builder
+	Write(&b, []Family{{Name: "h", Help: "H.", Type: TypeHistogram, Samples: samples}})
+	want := `# HELP h H.
+# TYPE h histogram
+h_bucket{device="a",le="1"} 3
+h_bucket{device="a",le="2"} 3
+h_bucket{device="a",le="+Inf"} 5
+h_sum{device="a"} 7.5
+h_count{device="a"} 5
+`
+	if b.String() != want {
+		t.Errorf("histogramSamples() =\n%s\nwant\n%s", b.String(), want)
+	}
+	if len(labels) != 1 {
+		t.Errorf("histogramSamples() modified labels: %v", labels)
+	}
+}
@@ -0,0 +1,144 @@
+package mqtt
+
+import (
+	"bufio"
+	"encoding/binary"
+	"net"
+	"sync"
+	"testing"
+)
+
+// received is a message seen by the test broker
+type received struct {
+	Message
+	dup bool
+}
+
+// testBroker is an in-process MQTT 3.1.1 broker for the publishing side:
+// it accepts connections, acknowledges publishes, keeps retained messages
+// and publishes the will of connections that close without DISCONNECT
+type testBroker struct {
+	t        *testing.T
+	listener net.Listener
+	messages chan received
+
+	mu       sync.Mutex
+	retained map[string]Message
+	conns    []net.Conn
+	// dropNext closes the connection instead of acknowledging the next
+	// QoS 1 publish
+	dropNext bool
+}
+
+func newTestBroker(t *testing.T) *testBroker {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	b := &testBroker{t: t, listener: l, messages: make(chan received, 100), retained: make(map[string]Message)}
+	go b.serve()
+	t.Cleanup(func() {
+		l.Close()
+		b.kill()
+	})
+	return b
+}
+
+func (b *testBroker) addr() string {
+	return b.listener.Addr().String()
+}
+
+// kill drops all connections without DISCONNECT
+func (b *testBroker) kill() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, c := range b.conns {
+		c.Close()
+	}
+	b.conns = nil
+}
+
+func (b *testBroker) serve() {
+	for {
+		c, err := b.listener.Accept()
+		if err != nil {
+			return
+		}
+		b.mu.Lock()
+		b.conns = append(b.conns, c)
+		b.mu.Unlock()
+		go b.handle(c)
+	}
+}
+
+func (b *testBroker) deliver(m Message, dup bool) {
+	b.mu.Lock()
+	if m.Retain {
+		b.retained[m.Topic] = m
+	}
+	b.mu.Unlock()
+	b.messages <- received{Message: m, dup: dup}
+}
+
+func (b *testBroker) handle(c net.Conn) {
+	defer c.Close()
+	r := bufio.NewReader(c)
+	p, err := readPacket(r)
+	if err != nil || p.kind != packetConnect {
+		return
+	}
+	will, ok := parseConnect(p)
+	if !ok {
+		writePacket(c, packet{kind: packetConnack, body: []byte{0, 1}})
+		return
+	}
+	writePacket(c, packet{kind: packetConnack, body: []byte{0, 0}})
+
+	for {
+		p, err := readPacket(r)
+		if err != nil {
+			if will != nil {
+				b.deliver(*will, false)
+			}
+			return
+		}
+		switch p.kind {
+		case packetPublish:
+			m, id, err := parsePublish(p)
+			if err != nil {
+				return
+			}
+			if m.QoS > 0 {
+				b.mu.Lock()
+				drop := b.dropNext
+				b.dropNext = false
+				b.mu.Unlock()
+				if drop {
+					return
+				}
+			}
+			b.deliver(m, p.flags&0x08 != 0)
+			if m.QoS > 0 {
+				writePacket(c, packet{kind: packetPuback, body: binary.BigEndian.AppendUint16(nil, id)})
+			}
+		case packetPingreq:
+			writePacket(c, packet{kind: packetPingresp})
+		case packetDisconnect:
+			return
+		}
+	}
+}
+
+// parseConnect returns the will of a CONNECT packet, ok is false for
+// other protocol versions
+func parseConnect(p packet) (will *Message, ok bool) {
+	r := reader{buf: p.body}
+	name, level, flags := r.string(), r.byte(), r.byte()
+	r.uint16()
+	r.string()
+	if flags&0x04 != 0 {
+		will = &Message{Topic: r.string(), Payload: r.bytes(), QoS: flags >> 3 & 0x03, Retain: flags&0x20 != 0}
+	}
+	return will, r.err == nil && name == "MQTT" && level == 4
+}
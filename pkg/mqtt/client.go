@@ -0,0 +1,319 @@
+// Package mqtt is a minimal MQTT 3.1.1 publishing client with QoS 0 and 1,
+// last will and automatic reconnection
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrClosed is returned when publishing on a client that has stopped
+var ErrClosed = errors.New("mqtt client closed")
+
+// connackErrors are the CONNACK return codes
+var connackErrors = map[byte]string{
+	1: "unacceptable protocol version",
+	2: "identifier rejected",
+	3: "server unavailable",
+	4: "bad user name or password",
+	5: "not authorized",
+}
+
+// Options configures a Client
+type Options struct {
+	// Broker is the broker address, host:port or tcp://host:port
+	Broker   string
+	ClientID string
+	Username string
+	Password string
+	// KeepAlive is the keep alive interval, default 30s
+	KeepAlive time.Duration
+	// CleanSession asks the broker to discard the session state
+	CleanSession bool
+	// Will is published by the broker when the connection is lost
+	Will *Message
+	// ConnectTimeout bounds the dial and CONNACK, default 10s
+	ConnectTimeout time.Duration
+	// ReconnectDelay is the first reconnection delay, doubled up to
+	// MaxReconnectDelay, defaults 1s and 1m
+	ReconnectDelay    time.Duration
+	MaxReconnectDelay time.Duration
+	// OnConnect is called after every successful connection, it is
+	// usually used to publish a retained online status
+	OnConnect func(ctx context.Context, c *Client)
+}
+
+// Validate checks the options. MQTT 3.1.1 does not allow a password
+// without a user name (MQTT-3.1.2-22).
+func (o *Options) Validate() error {
+	if o.Broker == "" {
+		return errors.New("missing mqtt broker")
+	}
+	if o.Password != "" && o.Username == "" {
+		return errors.New("mqtt password requires a user name")
+	}
+	if o.Will != nil {
+		return o.Will.validate()
+	}
+	return nil
+}
+
+// Client publishes messages to a broker. Run maintains the connection,
+// Publish may be called from any goroutine.
+type Client struct {
+	opts Options
+
+	mu       sync.Mutex
+	conn     *conn
+	ready    chan struct{} // closed while connected
+	stopped  bool
+	stopping chan struct{}
+	nextID   uint16
+}
+
+// NewClient returns a client, call Run to connect
+func NewClient(opts Options) *Client {
+	if opts.KeepAlive <= 0 {
+		opts.KeepAlive = 30 * time.Second
+	}
+	if opts.ConnectTimeout <= 0 {
+		opts.ConnectTimeout = 10 * time.Second
+	}
+	if opts.ReconnectDelay <= 0 {
+		opts.ReconnectDelay = time.Second
+	}
+	if opts.MaxReconnectDelay < opts.ReconnectDelay {
+		opts.MaxReconnectDelay = max(time.Minute, opts.ReconnectDelay)
+	}
+	opts.Broker = strings.TrimPrefix(opts.Broker, "tcp://")
+	return &Client{opts: opts, ready: make(chan struct{}), stopping: make(chan struct{})}
+}
+
+// Run connects and reconnects to the broker until ctx is done. It then
+// publishes the will itself and disconnects cleanly, as the broker
+// discards the will on a clean disconnect, so that subscribers see the
+// same status after a shutdown as after a lost connection.
+func (c *Client) Run(ctx context.Context) error {
+	defer c.stop()
+	if err := c.opts.Validate(); err != nil {
+		return err
+	}
+	delay := c.opts.ReconnectDelay
+	for {
+		cn, err := c.connect(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			slog.Warn("mqtt connect failed", "broker", c.opts.Broker, "error", err, "retry", delay)
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(delay):
+			}
+			delay = min(delay*2, c.opts.MaxReconnectDelay)
+			continue
+		}
+		delay = c.opts.ReconnectDelay
+		slog.Debug("mqtt connected", "broker", c.opts.Broker)
+
+		c.mu.Lock()
+		c.conn = cn
+		close(c.ready)
+		c.mu.Unlock()
+		if c.opts.OnConnect != nil {
+			go c.opts.OnConnect(ctx, c)
+		}
+
+		select {
+		case <-ctx.Done():
+			c.publishWill(cn)
+			cn.disconnect()
+			return nil
+		case <-cn.done:
+			slog.Warn("mqtt connection lost", "broker", c.opts.Broker, "error", cn.err)
+		}
+		c.mu.Lock()
+		c.conn = nil
+		c.ready = make(chan struct{})
+		c.mu.Unlock()
+	}
+}
+
+// publishWill publishes the will on cn, waiting at most ConnectTimeout
+// for the acknowledgement of QoS 1
+func (c *Client) publishWill(cn *conn) {
+	w := c.opts.Will
+	if w == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), c.opts.ConnectTimeout)
+	defer cancel()
+	var id uint16
+	if w.QoS > 0 {
+		id = c.packetID()
+	}
+	if err := cn.publish(ctx, *w, id, false); err != nil {
+		slog.Warn("mqtt failed to publish will", "topic", w.Topic, "error", err)
+	}
+}
+
+func (c *Client) stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.stopped {
+		c.stopped = true
+		close(c.stopping)
+	}
+}
+
+// Connected reports whether the client is connected
+func (c *Client) Connected() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.conn != nil
+}
+
+func (c *Client) connect(ctx context.Context) (*conn, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.opts.ConnectTimeout)
+	defer cancel()
+	var dialer net.Dialer
+	nc, err := dialer.DialContext(ctx, "tcp", c.opts.Broker)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		nc.SetDeadline(deadline)
+	}
+	r := bufio.NewReader(nc)
+	if err := writePacket(nc, c.connectPacket()); err != nil {
+		nc.Close()
+		return nil, err
+	}
+	p, err := readPacket(r)
+	if err != nil {
+		nc.Close()
+		return nil, fmt.Errorf("failed to read connack: %w", err)
+	}
+	if p.kind != packetConnack || len(p.body) != 2 {
+		nc.Close()
+		return nil, fmt.Errorf("unexpected packet %d waiting for connack", p.kind)
+	}
+	if code := p.body[1]; code != 0 {
+		nc.Close()
+		if reason, ok := connackErrors[code]; ok {
+			return nil, fmt.Errorf("connection refused: %s", reason)
+		}
+		return nil, fmt.Errorf("connection refused: code %d", code)
+	}
+	nc.SetDeadline(time.Time{})
+	return newConn(nc, r, c.opts.KeepAlive), nil
+}
+
+func (c *Client) connectPacket() packet {
+	var flags byte
+	if c.opts.CleanSession {
+		flags |= 0x02
+	}
+	if w := c.opts.Will; w != nil {
+		flags |= 0x04 | w.QoS<<3
+		if w.Retain {
+			flags |= 0x20
+		}
+	}
+	if c.opts.Username != "" {
+		flags |= 0x80
+	}
+	if c.opts.Password != "" {
+		flags |= 0x40
+	}
+	body := appendString(nil, "MQTT")
+	body = append(body, 4, flags)
+	body = binary.BigEndian.AppendUint16(body, uint16(c.opts.KeepAlive/time.Second))
+	body = appendString(body, c.opts.ClientID)
+	if w := c.opts.Will; w != nil {
+		body = appendString(body, w.Topic)
+		body = appendString(body, string(w.Payload))
+	}
+	if c.opts.Username != "" {
+		body = appendString(body, c.opts.Username)
+	}
+	if c.opts.Password != "" {
+		body = appendString(body, c.opts.Password)
+	}
+	return packet{kind: packetConnect, body: body}
+}
+
+// Publish sends m, waiting for a connection if needed. QoS 1 messages
+// are resent after a reconnection until the broker acknowledges them.
+func (c *Client) Publish(ctx context.Context, m Message) error {
+	if err := m.validate(); err != nil {
+		return err
+	}
+	var id uint16
+	if m.QoS > 0 {
+		id = c.packetID()
+	}
+	for dup := false; ; dup = true {
+		cn, err := c.waitConn(ctx)
+		if err != nil {
+			return err
+		}
+		err = cn.publish(ctx, m, id, dup)
+		if err == nil || ctx.Err() != nil || !errors.Is(err, errConnLost) {
+			return err
+		}
+		if m.QoS == 0 {
+			return err
+		}
+	}
+}
+
+// packetID returns the next non zero packet identifier
+func (c *Client) packetID() uint16 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nextID++
+	if c.nextID == 0 {
+		c.nextID = 1
+	}
+	return c.nextID
+}
+
+func (c *Client) waitConn(ctx context.Context) (*conn, error) {
+	for {
+		c.mu.Lock()
+		cn, ready, stopped := c.conn, c.ready, c.stopped
+		c.mu.Unlock()
+		if cn != nil {
+			return cn, nil
+		}
+		if stopped {
+			return nil, ErrClosed
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-c.stopping:
+			return nil, ErrClosed
+		case <-ready:
+		}
+	}
+}
+
+func writePacket(w interface{ Write([]byte) (int, error) }, p packet) error {
+	data, err := p.encode()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
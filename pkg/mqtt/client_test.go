@@ -0,0 +1,185 @@
+package mqtt
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPacket_RemainingLength(t *testing.T) {
+	for _, n := range []int{0, 127, 128, 16383, 16384, 2_097_152} {
+		p := packet{kind: packetPublish, flags: 0x03, body: bytes.Repeat([]byte{'x'}, n)}
+		data, err := p.encode()
+		if err != nil {
+			t.Fatalf("encode() error = %v", err)
+		}
+		got, err := readPacket(bufio.NewReader(bytes.NewReader(data)))
+		if err != nil {
+			t.Fatalf("readPacket(%d) error = %v", n, err)
+		}
+		if got.kind != p.kind || got.flags != p.flags || len(got.body) != n {
+			t.Errorf("readPacket(%d) = %d %d %d bytes", n, got.kind, got.flags, len(got.body))
+		}
+	}
+}
+
+func TestPublishPacket_RoundTrip(t *testing.T) {
+	m := Message{Topic: "pira/a/rds/ps", Payload: []byte("RADIO 1 "), QoS: 1, Retain: true}
+	got, id, err := parsePublish(publishPacket(m, 42, true))
+	if err != nil {
+		t.Fatalf("parsePublish() error = %v", err)
+	}
+	if id != 42 || got.Topic != m.Topic || string(got.Payload) != string(m.Payload) || got.QoS != 1 || !got.Retain {
+		t.Errorf("parsePublish() = %+v, %d", got, id)
+	}
+}
+
+// next waits for the next message received by the broker
+func next(t *testing.T, b *testBroker) received {
+	t.Helper()
+	select {
+	case m := <-b.messages:
+		return m
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message received")
+	}
+	return received{}
+}
+
+// runClient runs a client until the test ends or stop is called, stop
+// returns the error of Run
+func runClient(t *testing.T, opts Options) (c *Client, stop func() error) {
+	t.Helper()
+	c = NewClient(opts)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- c.Run(ctx) }()
+	stop = sync.OnceValue(func() error {
+		cancel()
+		return <-done
+	})
+	t.Cleanup(func() { stop() })
+	return c, stop
+}
+
+func TestClient_Publish(t *testing.T) {
+	b := newTestBroker(t)
+	c, _ := runClient(t, Options{Broker: "tcp://" + b.addr(), ClientID: "test"})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for _, m := range []Message{
+		{Topic: "pira/a/am", Payload: []byte("3")},
+		{Topic: "pira/a/rds/ps", Payload: []byte("RADIO 1 "), QoS: 1, Retain: true},
+	} {
+		if err := c.Publish(ctx, m); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+		got := next(t, b)
+		if got.Topic != m.Topic || string(got.Payload) != string(m.Payload) || got.QoS != m.QoS || got.Retain != m.Retain {
+			t.Errorf("broker received %+v, want %+v", got.Message, m)
+		}
+	}
+	b.mu.Lock()
+	retained := b.retained["pira/a/rds/ps"]
+	b.mu.Unlock()
+	if string(retained.Payload) != "RADIO 1 " {
+		t.Errorf("retained = %q", retained.Payload)
+	}
+	if err := c.Publish(ctx, Message{Topic: "x", QoS: 2}); err == nil {
+		t.Error("Publish() with QoS 2, want error")
+	}
+}
+
+func TestClient_WillAndReconnect(t *testing.T) {
+	b := newTestBroker(t)
+	connected := make(chan struct{}, 10)
+	will := &Message{Topic: "pira/a/status", Payload: []byte("offline"), Retain: true}
+	_, stop := runClient(t, Options{
+		Broker:         b.addr(),
+		ClientID:       "test",
+		Will:           will,
+		ReconnectDelay: 10 * time.Millisecond,
+		OnConnect: func(ctx context.Context, c *Client) {
+			c.Publish(ctx, Message{Topic: "pira/a/status", Payload: []byte("online"), Retain: true})
+			connected <- struct{}{}
+		},
+	})
+
+	if got := next(t, b); string(got.Payload) != "online" {
+		t.Fatalf("first message = %q, want online", got.Payload)
+	}
+	// a lost connection publishes the will, then the client reconnects
+	b.kill()
+	if got := next(t, b); got.Topic != will.Topic || string(got.Payload) != "offline" {
+		t.Fatalf("after connection loss = %+v, want will", got.Message)
+	}
+	if got := next(t, b); string(got.Payload) != "online" {
+		t.Fatalf("after reconnection = %q, want online", got.Payload)
+	}
+
+	// a clean shutdown publishes offline before disconnecting
+	if err := stop(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if got := next(t, b); got.Topic != will.Topic || string(got.Payload) != "offline" || !got.Retain {
+		t.Errorf("after clean shutdown = %+v, want retained offline", got.Message)
+	}
+	select {
+	case m := <-b.messages:
+		t.Errorf("after disconnect received %+v", m.Message)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestClient_QoS1Resend(t *testing.T) {
+	b := newTestBroker(t)
+	b.dropNext = true
+	c, _ := runClient(t, Options{Broker: b.addr(), ClientID: "test", ReconnectDelay: 10 * time.Millisecond})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := c.Publish(ctx, Message{Topic: "pira/a/alerts", Payload: []byte("{}"), QoS: 1}); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	got := next(t, b)
+	if got.Topic != "pira/a/alerts" || !got.dup {
+		t.Errorf("broker received %+v dup %v, want resent message", got.Message, got.dup)
+	}
+}
+
+func TestClient_PublishAfterStop(t *testing.T) {
+	b := newTestBroker(t)
+	c, stop := runClient(t, Options{Broker: b.addr()})
+	stop()
+	if err := c.Publish(context.Background(), Message{Topic: "x"}); err != ErrClosed {
+		t.Errorf("Publish() error = %v, want %v", err, ErrClosed)
+	}
+}
+
+func TestOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{"valid", Options{Broker: "localhost:1883", Username: "u", Password: "p"}, false},
+		{"no broker", Options{}, true},
+		{"password without user name", Options{Broker: "localhost:1883", Password: "p"}, true},
+		{"invalid will", Options{Broker: "localhost:1883", Will: &Message{}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.opts.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+	err := NewClient(Options{Broker: "localhost:1883", Password: "p"}).Run(context.Background())
+	if err == nil {
+		t.Error("Run() with a password without user name, want error")
+	}
+}
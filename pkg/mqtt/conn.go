@@ -0,0 +1,142 @@
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// errConnLost is returned by publishes interrupted by a lost connection
+var errConnLost = errors.New("mqtt connection lost")
+
+// conn is an established connection
+type conn struct {
+	nc        net.Conn
+	keepAlive time.Duration
+
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	pending map[uint16]chan struct{}
+
+	done     chan struct{}
+	err      error
+	doneOnce sync.Once
+}
+
+func newConn(nc net.Conn, r *bufio.Reader, keepAlive time.Duration) *conn {
+	c := &conn{
+		nc:        nc,
+		keepAlive: keepAlive,
+		pending:   make(map[uint16]chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go c.readLoop(r)
+	go c.pingLoop()
+	return c
+}
+
+func (c *conn) close(err error) {
+	c.doneOnce.Do(func() {
+		c.err = err
+		c.nc.Close()
+		close(c.done)
+	})
+}
+
+func (c *conn) write(p packet) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.nc.SetWriteDeadline(time.Now().Add(c.keepAlive))
+	if err := writePacket(c.nc, p); err != nil {
+		c.close(err)
+		return fmt.Errorf("%w: %w", errConnLost, err)
+	}
+	return nil
+}
+
+// readLoop handles acknowledgements, the connection is considered lost
+// when nothing arrives within one and a half keep alive intervals
+func (c *conn) readLoop(r *bufio.Reader) {
+	for {
+		c.nc.SetReadDeadline(time.Now().Add(c.keepAlive * 3 / 2))
+		p, err := readPacket(r)
+		if err != nil {
+			c.close(err)
+			return
+		}
+		switch p.kind {
+		case packetPuback:
+			if len(p.body) < 2 {
+				c.close(errMalformed)
+				return
+			}
+			id := binary.BigEndian.Uint16(p.body)
+			c.mu.Lock()
+			if ack, ok := c.pending[id]; ok {
+				close(ack)
+				delete(c.pending, id)
+			}
+			c.mu.Unlock()
+		case packetPingresp:
+		default:
+			c.close(fmt.Errorf("unexpected mqtt packet %d", p.kind))
+			return
+		}
+	}
+}
+
+func (c *conn) pingLoop() {
+	ticker := time.NewTicker(c.keepAlive / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			if c.write(packet{kind: packetPingreq}) != nil {
+				return
+			}
+		}
+	}
+}
+
+func (c *conn) publish(ctx context.Context, m Message, id uint16, dup bool) error {
+	var ack chan struct{}
+	if m.QoS > 0 {
+		ack = make(chan struct{})
+		c.mu.Lock()
+		c.pending[id] = ack
+		c.mu.Unlock()
+		defer func() {
+			c.mu.Lock()
+			delete(c.pending, id)
+			c.mu.Unlock()
+		}()
+	}
+	if err := c.write(publishPacket(m, id, dup)); err != nil {
+		return err
+	}
+	if ack == nil {
+		return nil
+	}
+	select {
+	case <-ack:
+		return nil
+	case <-c.done:
+		return errConnLost
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// disconnect sends DISCONNECT, the broker then discards the will
+func (c *conn) disconnect() {
+	c.write(packet{kind: packetDisconnect})
+	c.close(nil)
+}
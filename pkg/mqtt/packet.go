@@ -0,0 +1,178 @@
+package mqtt
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Control packet types of MQTT 3.1.1
+const (
+	packetConnect    byte = 1
+	packetConnack    byte = 2
+	packetPublish    byte = 3
+	packetPuback     byte = 4
+	packetSubscribe  byte = 8
+	packetSuback     byte = 9
+	packetPingreq    byte = 12
+	packetPingresp   byte = 13
+	packetDisconnect byte = 14
+)
+
+// maxRemainingLength is the largest remaining length of a packet
+const maxRemainingLength = 268_435_455
+
+var errMalformed = errors.New("malformed mqtt packet")
+
+// packet is a control packet, flags are the low nibble of the fixed header
+type packet struct {
+	kind  byte
+	flags byte
+	body  []byte
+}
+
+func readPacket(r *bufio.Reader) (packet, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return packet{}, err
+	}
+	length, multiplier := 0, 1
+	for i := 0; ; i++ {
+		if i == 4 {
+			return packet{}, errMalformed
+		}
+		b, err := r.ReadByte()
+		if err != nil {
+			return packet{}, err
+		}
+		length += int(b&0x7F) * multiplier
+		multiplier *= 128
+		if b&0x80 == 0 {
+			break
+		}
+	}
+	p := packet{kind: header >> 4, flags: header & 0x0F, body: make([]byte, length)}
+	if _, err := io.ReadFull(r, p.body); err != nil {
+		return packet{}, err
+	}
+	return p, nil
+}
+
+func (p packet) encode() ([]byte, error) {
+	length := len(p.body)
+	if length > maxRemainingLength {
+		return nil, fmt.Errorf("mqtt packet too large: %d bytes", length)
+	}
+	buf := make([]byte, 0, length+5)
+	buf = append(buf, p.kind<<4|p.flags)
+	for {
+		b := byte(length % 128)
+		length /= 128
+		if length > 0 {
+			b |= 0x80
+		}
+		buf = append(buf, b)
+		if length == 0 {
+			break
+		}
+	}
+	return append(buf, p.body...), nil
+}
+
+func appendString(buf []byte, s string) []byte {
+	buf = binary.BigEndian.AppendUint16(buf, uint16(len(s)))
+	return append(buf, s...)
+}
+
+// reader consumes the variable header and payload of a packet
+type reader struct {
+	buf []byte
+	err error
+}
+
+func (r *reader) uint16() uint16 {
+	if len(r.buf) < 2 {
+		r.err = errMalformed
+		return 0
+	}
+	v := binary.BigEndian.Uint16(r.buf)
+	r.buf = r.buf[2:]
+	return v
+}
+
+func (r *reader) byte() byte {
+	if len(r.buf) < 1 {
+		r.err = errMalformed
+		return 0
+	}
+	b := r.buf[0]
+	r.buf = r.buf[1:]
+	return b
+}
+
+func (r *reader) bytes() []byte {
+	n := int(r.uint16())
+	if len(r.buf) < n {
+		r.err = errMalformed
+		return nil
+	}
+	b := r.buf[:n]
+	r.buf = r.buf[n:]
+	return b
+}
+
+func (r *reader) string() string {
+	return string(r.bytes())
+}
+
+// Message is an application message
+type Message struct {
+	Topic   string
+	Payload []byte
+	QoS     byte
+	Retain  bool
+}
+
+func (m *Message) validate() error {
+	if m.Topic == "" {
+		return errors.New("empty mqtt topic")
+	}
+	if m.QoS > 1 {
+		return fmt.Errorf("unsupported mqtt qos %d", m.QoS)
+	}
+	return nil
+}
+
+// publishPacket encodes a PUBLISH packet, id is only used for QoS 1
+func publishPacket(m Message, id uint16, dup bool) packet {
+	flags := m.QoS << 1
+	if m.Retain {
+		flags |= 0x01
+	}
+	if dup {
+		flags |= 0x08
+	}
+	body := appendString(nil, m.Topic)
+	if m.QoS > 0 {
+		body = binary.BigEndian.AppendUint16(body, id)
+	}
+	return packet{kind: packetPublish, flags: flags, body: append(body, m.Payload...)}
+}
+
+// parsePublish decodes a PUBLISH packet
+func parsePublish(p packet) (m Message, id uint16, err error) {
+	r := reader{buf: p.body}
+	m.Topic = r.string()
+	m.QoS = p.flags >> 1 & 0x03
+	m.Retain = p.flags&0x01 != 0
+	if m.QoS > 0 {
+		id = r.uint16()
+	}
+	if r.err != nil {
+		return Message{}, 0, r.err
+	}
+	m.Payload = r.buf
+	return m, id, nil
+}
@@ -0,0 +1,136 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-pira/pkg/alert"
+	"go-pira/pkg/pira"
+)
+
+// Status payloads of the <prefix>/status topic
+const (
+	StatusOnline  = "online"
+	StatusOffline = "offline"
+)
+
+// field is an FMInfo value published to its own topic
+type field struct {
+	topic string
+	value func(fmi *pira.FMInfo) string
+}
+
+func formatUint[T uint8 | uint16 | uint32](v T) string {
+	return strconv.FormatUint(uint64(v), 10)
+}
+
+var fields = []field{
+	{"frequency", func(f *pira.FMInfo) string { return formatUint(f.Frequency) }},
+	{"deviation/current", func(f *pira.FMInfo) string { return formatUint(f.Deviation) }},
+	{"deviation/peak", func(f *pira.FMInfo) string { return formatUint(f.DeviationMax) }},
+	{"deviation/average", func(f *pira.FMInfo) string { return formatUint(f.DeviationAverage) }},
+	{"deviation/min_hold", func(f *pira.FMInfo) string { return formatUint(f.DeviationMinHold) }},
+	{"deviation/max_hold", func(f *pira.FMInfo) string { return formatUint(f.DeviationMaxHold) }},
+	{"pilot/deviation", func(f *pira.FMInfo) string { return formatUint(f.PilotDeviation) }},
+	{"rds/deviation", func(f *pira.FMInfo) string { return formatUint(f.RDSDeviation) }},
+	{"rds/phase_difference", func(f *pira.FMInfo) string { return strconv.Itoa(int(f.RDSPhaseDifference)) }},
+	{"modulation/power", func(f *pira.FMInfo) string { return strconv.FormatFloat(f.ModulationPower, 'f', -1, 64) }},
+	{"signal/quality", func(f *pira.FMInfo) string { return strconv.Itoa(f.SignalQuality) }},
+	{"signal/noise", func(f *pira.FMInfo) string { return formatUint(f.NoiseLevel) }},
+	{"am", func(f *pira.FMInfo) string { return formatUint(f.AM) }},
+	{"rds/pi", func(f *pira.FMInfo) string { return f.RDS.PI.String() }},
+	{"rds/ps", func(f *pira.FMInfo) string { return f.RDS.PS }},
+	{"rds/rt", func(f *pira.FMInfo) string { return strings.TrimRight(f.RDS.RT, "\r\x00 ") }},
+	{"rds/pty", func(f *pira.FMInfo) string { return f.RDS.PTY.String() }},
+	{"rds/ta", func(f *pira.FMInfo) string { return strconv.FormatBool(f.RDS.Status.TA) }},
+	{"rds/tp", func(f *pira.FMInfo) string { return strconv.FormatBool(f.RDS.Status.TP) }},
+}
+
+// Publisher publishes analyzer readings under a topic prefix, usually
+// pira/<device>:
+//
+//	<prefix>/status              online or offline, retained, offline is the will
+//	<prefix>/deviation/peak      one topic per field, retained
+//	<prefix>/snapshot            JSON snapshot
+//	<prefix>/events              JSON change events
+//	<prefix>/alerts              JSON alert events
+type Publisher struct {
+	client *Client
+	prefix string
+	qos    byte
+	// Timeout bounds the publishes of alert events, which have no
+	// context, callers can use it to bound their own publishes
+	Timeout time.Duration
+}
+
+// NewPublisher returns a publisher and the client it publishes on. The
+// will and client ID of opts are set from prefix. The online status is
+// published on every connection and the offline status, the will, when
+// the connection is lost or the client stops.
+func NewPublisher(opts Options, prefix string, qos byte) *Publisher {
+	prefix = strings.TrimSuffix(prefix, "/")
+	p := &Publisher{prefix: prefix, qos: qos, Timeout: 10 * time.Second}
+	opts.Will = &Message{Topic: prefix + "/status", Payload: []byte(StatusOffline), QoS: qos, Retain: true}
+	if opts.ClientID == "" {
+		opts.ClientID = strings.ReplaceAll(prefix, "/", "-")
+	}
+	onConnect := opts.OnConnect
+	opts.OnConnect = func(ctx context.Context, c *Client) {
+		p.publish(ctx, "status", []byte(StatusOnline), true)
+		if onConnect != nil {
+			onConnect(ctx, c)
+		}
+	}
+	p.client = NewClient(opts)
+	return p
+}
+
+// Client returns the client to run
+func (p *Publisher) Client() *Client {
+	return p.client
+}
+
+func (p *Publisher) publish(ctx context.Context, topic string, payload []byte, retain bool) error {
+	return p.client.Publish(ctx, Message{Topic: p.prefix + "/" + topic, Payload: payload, QoS: p.qos, Retain: retain})
+}
+
+func (p *Publisher) publishJSON(ctx context.Context, topic string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return p.publish(ctx, topic, data, false)
+}
+
+// PublishSnapshot publishes the fields of a snapshot as retained values
+// and the snapshot as JSON, failed reads only publish the snapshot
+func (p *Publisher) PublishSnapshot(ctx context.Context, s pira.Snapshot) error {
+	var errs []error
+	if s.FMInfo != nil {
+		for _, f := range fields {
+			errs = append(errs, p.publish(ctx, f.topic, []byte(f.value(s.FMInfo)), true))
+		}
+	}
+	errs = append(errs, p.publishJSON(ctx, "snapshot", s))
+	return errors.Join(errs...)
+}
+
+// PublishEvents publishes change events
+func (p *Publisher) PublishEvents(ctx context.Context, events []pira.Event) error {
+	var errs []error
+	for _, e := range events {
+		errs = append(errs, p.publishJSON(ctx, "events", e))
+	}
+	return errors.Join(errs...)
+}
+
+// Send implements the alert.Sink interface for Publisher
+func (p *Publisher) Send(e alert.Event) error {
+	ctx, cancel := context.WithTimeout(context.Background(), p.Timeout)
+	defer cancel()
+	return p.publishJSON(ctx, "alerts", e)
+}
@@ -0,0 +1,67 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"go-pira/pkg/alert"
+	"go-pira/pkg/pira"
+)
+
+func TestPublisher(t *testing.T) {
+	b := newTestBroker(t)
+	p := NewPublisher(Options{Broker: b.addr()}, "pira/p275/", 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- p.Client().Run(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	if got := next(t, b); got.Topic != "pira/p275/status" || string(got.Payload) != StatusOnline || !got.Retain {
+		t.Fatalf("first message = %+v, want retained online status", got.Message)
+	}
+
+	fmi := pira.FMInfo{DeviationMax: 75000, RDS: pira.RDSInfo{PI: 0x5201, PS: "RADIO 1 "}}
+	if err := p.PublishSnapshot(ctx, pira.Snapshot{Seq: 1, Time: time.Now(), FMInfo: &fmi}); err != nil {
+		t.Fatalf("PublishSnapshot() error = %v", err)
+	}
+	got := make(map[string]string)
+	for range len(fields) + 1 {
+		m := next(t, b)
+		got[m.Topic] = string(m.Payload)
+		if m.Topic != "pira/p275/snapshot" && !m.Retain {
+			t.Errorf("%s not retained", m.Topic)
+		}
+	}
+	for topic, want := range map[string]string{
+		"pira/p275/deviation/peak": "75000",
+		"pira/p275/rds/pi":         "5201",
+		"pira/p275/rds/ps":         "RADIO 1 ",
+		"pira/p275/rds/ta":         "false",
+	} {
+		if got[topic] != want {
+			t.Errorf("%s = %q, want %q", topic, got[topic], want)
+		}
+	}
+	var snapshot map[string]any
+	if err := json.Unmarshal([]byte(got["pira/p275/snapshot"]), &snapshot); err != nil || snapshot["seq"] != 1.0 {
+		t.Errorf("snapshot = %s, %v", got["pira/p275/snapshot"], err)
+	}
+
+	if err := p.Send(alert.Event{Type: alert.EventActive, Alert: alert.Alert{Rule: "peak"}}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if m := next(t, b); m.Topic != "pira/p275/alerts" {
+		t.Errorf("alert topic = %s", m.Topic)
+	}
+	if err := p.PublishEvents(ctx, []pira.Event{{Type: pira.EventTA, Field: "rds.status.ta"}}); err != nil {
+		t.Fatalf("PublishEvents() error = %v", err)
+	}
+	if m := next(t, b); m.Topic != "pira/p275/events" {
+		t.Errorf("event topic = %s", m.Topic)
+	}
+}
@@ -0,0 +1,89 @@
+package pira
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"go.bug.st/serial"
+)
+
+// Control commands, the set frequency command takes the frequency in the
+// units of FMInfo.Frequency in place of %d
+const (
+	CmdSetFrequency Command = "%d*F"
+	CmdResetHold    Command = "*H"
+)
+
+// ReadMemory reads n bytes of analyzer memory starting at addr
+func (p *Pira) ReadMemory(addr, n int) ([]byte, error) {
+	if addr < 0 || n <= 0 || addr+n > 0x1000 {
+		return nil, fmt.Errorf("invalid memory range %03X+%d", addr, n)
+	}
+	data := make([]byte, n)
+	if err := p.Load(addr, data); err != nil {
+		return nil, fmt.Errorf("failed to read memory: %w", err)
+	}
+	return data, nil
+}
+
+// Tuning range of SetFrequency in the units of FMInfo.Frequency
+const (
+	MinFrequency = 87_500
+	MaxFrequency = 108_000
+)
+
+// ErrFrequencyRange is returned when tuning outside of the FM band
+var ErrFrequencyRange = errors.New("frequency outside 87.5-108 MHz")
+
+// SetFrequency tunes the analyzer
+func (p *Pira) SetFrequency(frequency uint32) error {
+	if frequency < MinFrequency || frequency > MaxFrequency {
+		return fmt.Errorf("failed to set frequency: %w: %d kHz", ErrFrequencyRange, frequency)
+	}
+	if err := p.exec(Command(fmt.Sprintf(string(CmdSetFrequency), frequency))); err != nil {
+		return fmt.Errorf("failed to set frequency: %w", err)
+	}
+	return nil
+}
+
+// ResetHold resets the minimum and maximum hold deviations
+func (p *Pira) ResetHold() error {
+	if err := p.exec(CmdResetHold); err != nil {
+		return fmt.Errorf("failed to reset hold: %w", err)
+	}
+	return nil
+}
+
+// Exec sends a raw command and returns its response lines, a read
+// timeout ends the response as with the ?B command
+func (p *Pira) Exec(command Command) ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n, err := p.SendCommand(command)
+	if err != nil {
+		return nil, err
+	}
+	if n != len(command) {
+		return nil, fmt.Errorf("failed to send command %s", command)
+	}
+	var response []byte
+	for {
+		line, err := p.RecvResponse()
+		var portErr *serial.PortError
+		if errors.As(err, &portErr) && portErr.Code() == serial.ReadTimeout {
+			return response, nil
+		}
+		if err != nil {
+			return response, err
+		}
+		slog.Debug("response", "command", command, "response", string(line))
+		response = append(response, line...)
+	}
+}
+
+// exec sends a command and drains its response
+func (p *Pira) exec(command Command) error {
+	_, err := p.Exec(command)
+	return err
+}
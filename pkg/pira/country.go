@@ -0,0 +1,124 @@
+package pira
+
+import "fmt"
+
+// eccCountries maps the extended country code and the PI country nibble
+// to ISO 3166-1 alpha-2 country codes (IEC 62106 Annex D)
+var eccCountries = map[ECC][16]string{
+	// Europe
+	0xE0: {"", "DE", "DZ", "AD", "IL", "IT", "BE", "RU", "PS", "AL", "AT", "HU", "MT", "DE", "", "EG"},
+	0xE1: {"", "GR", "CY", "SM", "CH", "JO", "FI", "LU", "BG", "DK", "GI", "IQ", "GB", "LY", "RO", "FR"},
+	0xE2: {"", "MA", "CZ", "PL", "VA", "SK", "SY", "TN", "", "LI", "IS", "MC", "LT", "RS", "ES", "NO"},
+	0xE3: {"", "ME", "IE", "TR", "MK", "", "", "", "NL", "LV", "LB", "AZ", "HR", "KZ", "SE", "BY"},
+	0xE4: {"", "MD", "EE", "KG", "", "", "UA", "XK", "PT", "SI", "AM", "UZ", "GE", "", "TM", "BA"},
+	// Americas
+	0xA0: {"", "US", "US", "US", "US", "US", "US", "US", "US", "US", "US", "US", "", "US", "US", ""},
+	0xA1: {"", "", "", "", "", "", "", "", "", "", "", "CA", "CA", "CA", "CA", "GL"},
+	0xA2: {"", "AI", "AG", "EC", "FK", "BB", "BZ", "KY", "CR", "CU", "AR", "BR", "BM", "CW", "GP", "BS"},
+	0xA3: {"", "BO", "CO", "JM", "MQ", "GF", "PY", "NI", "", "PA", "DM", "DO", "CL", "GD", "TC", "GY"},
+	0xA4: {"", "GT", "HN", "AW", "", "MS", "TT", "PE", "SR", "UY", "KN", "LC", "SV", "HT", "VE", ""},
+	0xA5: {"", "", "", "", "", "", "", "", "", "", "", "MX", "VC", "MX", "MX", "MX"},
+	0xA6: {"", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "PM"},
+	// Africa
+	0xD0: {"", "CM", "CF", "DJ", "MG", "ML", "AO", "GQ", "GA", "GN", "ZA", "BF", "CG", "TG", "BJ", "MW"},
+	0xD1: {"", "NA", "LR", "GH", "MR", "ST", "CV", "SN", "GM", "BI", "SH", "BW", "KM", "TZ", "ET", "NG"},
+	0xD2: {"", "SL", "ZW", "MZ", "UG", "SZ", "KE", "SO", "NE", "TD", "GW", "CD", "CI", "", "ZM", "ER"},
+	// Asia and Pacific
+	0xF0: {"", "AU", "AU", "AU", "AU", "AU", "AU", "AU", "AU", "SA", "AF", "MM", "CN", "KP", "BH", "MY"},
+	0xF1: {"", "KI", "BT", "BD", "PK", "FJ", "OM", "NR", "IR", "NZ", "SB", "BN", "LK", "TW", "KR", "HK"},
+	0xF2: {"", "KW", "QA", "KH", "WS", "IN", "MO", "VN", "PH", "JP", "SG", "MV", "ID", "AE", "NP", "VU"},
+	0xF3: {"", "LA", "TH", "TO", "", "", "", "", "", "", "", "", "", "", "", ""},
+}
+
+// lookupCountry resolves the ISO 3166-1 alpha-2 code for an extended
+// country code and PI country nibble, empty if unknown
+func lookupCountry(ecc ECC, nibble byte) string {
+	countries, ok := eccCountries[ecc]
+	if !ok {
+		return ""
+	}
+	return countries[nibble&0x0F]
+}
+
+// countryNames are the English short names of the ISO 3166-1 codes above
+var countryNames = map[string]string{
+	"AD": "Andorra", "AE": "United Arab Emirates", "AF": "Afghanistan", "AG": "Antigua and Barbuda",
+	"AI": "Anguilla", "AL": "Albania", "AM": "Armenia", "AO": "Angola", "AR": "Argentina",
+	"AT": "Austria", "AU": "Australia", "AW": "Aruba", "AZ": "Azerbaijan",
+	"BA": "Bosnia and Herzegovina", "BB": "Barbados", "BD": "Bangladesh", "BE": "Belgium",
+	"BF": "Burkina Faso", "BG": "Bulgaria", "BH": "Bahrain", "BI": "Burundi", "BJ": "Benin",
+	"BM": "Bermuda", "BN": "Brunei", "BO": "Bolivia", "BR": "Brazil", "BS": "Bahamas",
+	"BT": "Bhutan", "BW": "Botswana", "BY": "Belarus", "BZ": "Belize",
+	"CA": "Canada", "CD": "DR Congo", "CF": "Central African Republic", "CG": "Congo",
+	"CH": "Switzerland", "CI": "Côte d'Ivoire", "CL": "Chile", "CM": "Cameroon", "CN": "China",
+	"CO": "Colombia", "CR": "Costa Rica", "CU": "Cuba", "CV": "Cabo Verde", "CW": "Curaçao",
+	"CY": "Cyprus", "CZ": "Czechia",
+	"DE": "Germany", "DJ": "Djibouti", "DK": "Denmark", "DM": "Dominica", "DO": "Dominican Republic",
+	"DZ": "Algeria",
+	"EC": "Ecuador", "EE": "Estonia", "EG": "Egypt", "ER": "Eritrea", "ES": "Spain", "ET": "Ethiopia",
+	"FI": "Finland", "FJ": "Fiji", "FK": "Falkland Islands", "FR": "France",
+	"GA": "Gabon", "GB": "United Kingdom", "GD": "Grenada", "GE": "Georgia", "GF": "French Guiana",
+	"GH": "Ghana", "GI": "Gibraltar", "GL": "Greenland", "GM": "Gambia", "GN": "Guinea",
+	"GP": "Guadeloupe", "GQ": "Equatorial Guinea", "GR": "Greece", "GT": "Guatemala",
+	"GW": "Guinea-Bissau", "GY": "Guyana",
+	"HK": "Hong Kong", "HN": "Honduras", "HR": "Croatia", "HT": "Haiti", "HU": "Hungary",
+	"ID": "Indonesia", "IE": "Ireland", "IL": "Israel", "IN": "India", "IQ": "Iraq", "IR": "Iran",
+	"IS": "Iceland", "IT": "Italy",
+	"JM": "Jamaica", "JO": "Jordan", "JP": "Japan",
+	"KE": "Kenya", "KG": "Kyrgyzstan", "KH": "Cambodia", "KI": "Kiribati", "KM": "Comoros",
+	"KN": "Saint Kitts and Nevis", "KP": "North Korea", "KR": "South Korea", "KW": "Kuwait",
+	"KY": "Cayman Islands", "KZ": "Kazakhstan",
+	"LA": "Laos", "LB": "Lebanon", "LC": "Saint Lucia", "LI": "Liechtenstein", "LK": "Sri Lanka",
+	"LR": "Liberia", "LT": "Lithuania", "LU": "Luxembourg", "LV": "Latvia", "LY": "Libya",
+	"MA": "Morocco", "MC": "Monaco", "MD": "Moldova", "ME": "Montenegro", "MG": "Madagascar",
+	"MK": "North Macedonia", "ML": "Mali", "MM": "Myanmar", "MO": "Macao", "MQ": "Martinique",
+	"MR": "Mauritania", "MS": "Montserrat", "MT": "Malta", "MV": "Maldives", "MW": "Malawi",
+	"MX": "Mexico", "MY": "Malaysia", "MZ": "Mozambique",
+	"NA": "Namibia", "NE": "Niger", "NG": "Nigeria", "NI": "Nicaragua", "NL": "Netherlands",
+	"NO": "Norway", "NP": "Nepal", "NR": "Nauru", "NZ": "New Zealand",
+	"OM": "Oman",
+	"PA": "Panama", "PE": "Peru", "PH": "Philippines", "PK": "Pakistan", "PL": "Poland",
+	"PM": "Saint Pierre and Miquelon", "PS": "Palestine", "PT": "Portugal", "PY": "Paraguay",
+	"QA": "Qatar",
+	"RO": "Romania", "RS": "Serbia", "RU": "Russia",
+	"SA": "Saudi Arabia", "SB": "Solomon Islands", "SE": "Sweden", "SG": "Singapore",
+	"SH": "Saint Helena", "SI": "Slovenia", "SK": "Slovakia", "SL": "Sierra Leone",
+	"SM": "San Marino", "SN": "Senegal", "SO": "Somalia", "SR": "Suriname",
+	"ST": "São Tomé and Príncipe", "SV": "El Salvador", "SY": "Syria", "SZ": "Eswatini",
+	"TC": "Turks and Caicos Islands", "TD": "Chad", "TG": "Togo", "TH": "Thailand",
+	"TM": "Turkmenistan", "TN": "Tunisia", "TO": "Tonga", "TR": "Türkiye",
+	"TT": "Trinidad and Tobago", "TW": "Taiwan", "TZ": "Tanzania",
+	"UA": "Ukraine", "UG": "Uganda", "US": "United States", "UY": "Uruguay", "UZ": "Uzbekistan",
+	"VA": "Vatican City", "VC": "Saint Vincent and the Grenadines", "VE": "Venezuela",
+	"VN": "Vietnam", "VU": "Vanuatu",
+	"WS": "Samoa",
+	"XK": "Kosovo",
+	"ZA": "South Africa", "ZM": "Zambia", "ZW": "Zimbabwe",
+}
+
+// Country is an ISO 3166-1 country
+type Country struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+// CountryByCode returns the country with the ISO 3166-1 alpha-2 code
+func CountryByCode(code string) (Country, bool) {
+	name, ok := countryNames[code]
+	if !ok {
+		return Country{}, false
+	}
+	return Country{Code: code, Name: name}, true
+}
+
+// ECC is the RDS extended country code
+type ECC byte
+
+// Country resolves the country together with the PI country nibble
+func (e ECC) Country(pi PI) (Country, bool) {
+	return CountryByCode(lookupCountry(e, pi.CountryNibble()))
+}
+
+func (e ECC) String() string {
+	return fmt.Sprintf("%02X", byte(e))
+}
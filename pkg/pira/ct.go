@@ -0,0 +1,126 @@
+package pira
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidCT is returned when the RDS clock time has not been
+// received or holds out of range values
+var ErrInvalidCT = errors.New("invalid rds ct")
+
+// mjdEpoch is day zero of the Modified Julian Date
+var mjdEpoch = time.Date(1858, time.November, 17, 0, 0, 0, 0, time.UTC)
+
+// MJD is the RDS Modified Julian Date as stored by the analyzer (17 bits, little endian)
+type MJD [3]byte
+
+// Day returns the day number since 1858-11-17
+func (m MJD) Day() uint32 {
+	return (uint32(m[0]) | uint32(m[1])<<8 | uint32(m[2])<<16) & 0x1FFFF
+}
+
+// IsValid reports whether a date has been received
+func (m MJD) IsValid() bool {
+	return m.Day() != 0
+}
+
+// Date returns midnight UTC of the date
+func (m MJD) Date() time.Time {
+	return mjdEpoch.AddDate(0, 0, int(m.Day()))
+}
+
+func (m MJD) String() string {
+	if !m.IsValid() {
+		return "not set"
+	}
+	return m.Date().Format(time.DateOnly)
+}
+
+// Offset returns the local time offset, transmitted as a sign bit
+// followed by five bits of half hours
+func (r *RDSCT) Offset() time.Duration {
+	offset := time.Duration(r.LocalTimeOffset&0x1F) * 30 * time.Minute
+	if r.LocalTimeOffset&0x20 != 0 {
+		return -offset
+	}
+	return offset
+}
+
+// UTC returns the broadcast time in UTC
+func (r *RDSCT) UTC() (time.Time, error) {
+	if !r.MJD.IsValid() || r.Hour > 23 || r.Minute > 59 {
+		return time.Time{}, ErrInvalidCT
+	}
+	date := r.MJD.Date()
+	return date.Add(time.Duration(r.Hour)*time.Hour + time.Duration(r.Minute)*time.Minute), nil
+}
+
+// Local returns the broadcast time in the transmitted local time zone
+func (r *RDSCT) Local() (time.Time, error) {
+	utc, err := r.UTC()
+	if err != nil {
+		return time.Time{}, err
+	}
+	offset := r.Offset()
+	return utc.In(time.FixedZone(formatOffset(offset), int(offset.Seconds()))), nil
+}
+
+func formatOffset(offset time.Duration) string {
+	sign := '+'
+	if offset < 0 {
+		sign = '-'
+		offset = -offset
+	}
+	return fmt.Sprintf("UTC%c%02d:%02d", sign, int(offset.Hours()), int(offset.Minutes())%60)
+}
+
+// MarshalJSON implements the json.Marshaler interface for RDSCT, it adds
+// the decoded UTC and local time when the clock time is valid
+func (r RDSCT) MarshalJSON() ([]byte, error) {
+	type rdsct RDSCT
+	v := struct {
+		rdsct
+		UTC   *time.Time `json:"utc,omitempty"`
+		Local *time.Time `json:"local,omitempty"`
+	}{rdsct: rdsct(r)}
+	if utc, err := r.UTC(); err == nil {
+		local, _ := r.Local()
+		v.UTC, v.Local = &utc, &local
+	}
+	return json.Marshal(v)
+}
+
+// ClockDrift compares the broadcast clock time against the host clock
+type ClockDrift struct {
+	Broadcast time.Time     `json:"broadcast"`
+	Host      time.Time     `json:"host"`
+	Drift     time.Duration `json:"drift"`
+}
+
+// Drift compares the clock time against host. CT is sent once a minute
+// with minute resolution, so host is truncated to the minute and drift
+// is positive when the broadcast clock runs ahead.
+func (r *RDSCT) Drift(host time.Time) (*ClockDrift, error) {
+	broadcast, err := r.UTC()
+	if err != nil {
+		return nil, err
+	}
+	host = host.UTC()
+	return &ClockDrift{
+		Broadcast: broadcast,
+		Host:      host,
+		Drift:     broadcast.Sub(host.Truncate(time.Minute)),
+	}, nil
+}
+
+// CheckRDSClock reads the RDS clock time and compares it against the host clock
+func (p *Pira) CheckRDSClock() (*ClockDrift, error) {
+	ct, err := p.GetRDSCT()
+	if err != nil {
+		return nil, err
+	}
+	return ct.Drift(time.Now())
+}
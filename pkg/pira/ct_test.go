@@ -0,0 +1,125 @@
+package pira
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mjdOf(day uint32) MJD {
+	return MJD{byte(day), byte(day >> 8), byte(day >> 16)}
+}
+
+func TestMJD_Date(t *testing.T) {
+	tests := []struct {
+		name string
+		mjd  MJD
+		want string
+	}{
+		{name: "epoch", mjd: mjdOf(0), want: "not set"},
+		{name: "day 60000", mjd: mjdOf(60000), want: "2023-02-25"},
+		{name: "leap day", mjd: mjdOf(60369), want: "2024-02-29"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.mjd.String(); got != tt.want {
+				t.Errorf("MJD.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRDSCT_Offset(t *testing.T) {
+	tests := []struct {
+		name   string
+		offset byte
+		want   time.Duration
+	}{
+		{name: "zero", offset: 0x00, want: 0},
+		{name: "plus two hours", offset: 0x04, want: 2 * time.Hour},
+		{name: "minus five and a half hours", offset: 0x2B, want: -(5*time.Hour + 30*time.Minute)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ct := RDSCT{LocalTimeOffset: tt.offset}
+			if got := ct.Offset(); got != tt.want {
+				t.Errorf("RDSCT.Offset() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRDSCT_Time(t *testing.T) {
+	ct := RDSCT{Hour: 22, Minute: 30, LocalTimeOffset: 0x04, MJD: mjdOf(60000)}
+
+	utc, err := ct.UTC()
+	if err != nil {
+		t.Fatalf("RDSCT.UTC() error = %v", err)
+	}
+	if want := time.Date(2023, 2, 25, 22, 30, 0, 0, time.UTC); !utc.Equal(want) {
+		t.Errorf("RDSCT.UTC() = %v, want %v", utc, want)
+	}
+
+	local, err := ct.Local()
+	if err != nil {
+		t.Fatalf("RDSCT.Local() error = %v", err)
+	}
+	if got := local.Format("2006-01-02 15:04 -07:00"); got != "2023-02-26 00:30 +02:00" {
+		t.Errorf("RDSCT.Local() = %v, want %v", got, "2023-02-26 00:30 +02:00")
+	}
+}
+
+func TestRDSCT_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		ct   RDSCT
+	}{
+		{name: "no date", ct: RDSCT{Hour: 12}},
+		{name: "hour out of range", ct: RDSCT{Hour: 24, MJD: mjdOf(60000)}},
+		{name: "minute out of range", ct: RDSCT{Minute: 60, MJD: mjdOf(60000)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.ct.UTC(); !errors.Is(err, ErrInvalidCT) {
+				t.Errorf("RDSCT.UTC() error = %v, want %v", err, ErrInvalidCT)
+			}
+		})
+	}
+}
+
+func TestRDSCT_Drift(t *testing.T) {
+	ct := RDSCT{Hour: 12, Minute: 0, MJD: mjdOf(60000)}
+	host := time.Date(2023, 2, 25, 11, 58, 40, 0, time.UTC)
+
+	drift, err := ct.Drift(host)
+	if err != nil {
+		t.Fatalf("RDSCT.Drift() error = %v", err)
+	}
+	if drift.Drift != 2*time.Minute {
+		t.Errorf("RDSCT.Drift() = %v, want %v", drift.Drift, 2*time.Minute)
+	}
+}
+
+func TestRDSCT_MarshalJSON(t *testing.T) {
+	ct := RDSCT{Hour: 12, Minute: 5, MJD: mjdOf(60000)}
+	data, err := json.Marshal(ct)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if !strings.Contains(string(data), `"utc":"2023-02-25T12:05:00Z"`) {
+		t.Errorf("json.Marshal() = %v, want utc field", string(data))
+	}
+
+	data, err = json.Marshal(RDSCT{})
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if strings.Contains(string(data), `"utc"`) {
+		t.Errorf("json.Marshal() = %v, want no utc field", string(data))
+	}
+}
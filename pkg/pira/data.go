@@ -123,7 +123,7 @@ type RDSStatus struct {
 	TP     bool   `json:"tp"`
 	TA     bool   `json:"ta"`
 	MS     bool   `json:"ms"`
-	DI     byte   `json:"di"`
+	DI     DI     `json:"di"`
 }
 
 // RTPlusItem is a single RT+ item
@@ -156,6 +156,7 @@ type RDSCT struct {
 	Hour            byte `json:"hour"`
 	Minute          byte `json:"minute"`
 	LocalTimeOffset byte `json:"local_time_offset"`
+	MJD             MJD  `json:"mjd"`
 }
 
 func (r *RDSCT) String() string {
@@ -163,34 +164,49 @@ func (r *RDSCT) String() string {
 }
 
 type RDSInfo struct {
-	PI     uint16      `json:"pi"`
+	PI     PI          `json:"pi"`
+	PIInfo PIInfo      `json:"pi_info"`
 	PS     string      `json:"ps"`
-	PTY    byte        `json:"pty"`
+	PTY    PTY         `json:"pty"`
 	Status RDSStatus   `json:"status"`
 	Groups [32]byte    `json:"groups"`
 	AFList [26]float64 `json:"af_list"`
-	EONPI  [4]uint16   `json:"eonpi"`
+	EONPI  [4]PI       `json:"eonpi"`
 	RT     string      `json:"rt"`
 	PTYN   string      `json:"ptyn"`
 	CT     RDSCT       `json:"ct"`
-	MJD    [3]byte     `json:"mjd"`
 	RTPlus RTPlus      `json:"rt_plus"`
 	PIN    RDSPIN      `json:"pin"`
-	LIC    byte        `json:"lic"`
-	ECC    byte        `json:"ecc"`
+	LIC    LIC         `json:"lic"`
+	ECC    ECC         `json:"ecc"`
 	LongPS string      `json:"long_ps"`
 }
 
+// MJD returns the date received with the clock time
+func (r *RDSInfo) MJD() MJD {
+	return r.CT.MJD
+}
+
 func (r *RDSInfo) String() string {
 	return fmt.Sprintf(
-		"PI: %d, PS: %s, PTY: %d, Status: %v, Groups: %v, AFList: %v, "+
+		"PI: %s, PS: %s, PTY: %s, Status: %v, Groups: %v, AFList: %v, "+
 			"EONPI: %v, RT: %s, PTYN: %s, CT: %v, MJD: %v, RTPlus: %v, PIN: %v, "+
-			"LIC: %d, ECC: %d",
+			"LIC: %s, ECC: %s",
 		r.PI, r.PS, r.PTY, r.Status, r.Groups, r.AFList, r.EONPI, r.RT,
-		r.PTYN, r.CT, r.MJD, r.RTPlus, r.PIN, r.LIC, r.ECC,
+		r.PTYN, r.CT, r.MJD(), r.RTPlus, r.PIN, r.LIC, r.ECC,
 	)
 }
 
+// Country resolves the country from the PI code and ECC
+func (r *RDSInfo) Country() (Country, bool) {
+	return r.ECC.Country(r.PI)
+}
+
+// Language returns the language announced by the LIC
+func (r *RDSInfo) Language() (Language, bool) {
+	return r.LIC.Language()
+}
+
 type FMInfo struct {
 	Frequency          uint32
 	PilotDeviation     uint32
@@ -201,6 +217,7 @@ type FMInfo struct {
 	ModulationPower    float64
 	DeviationMinHold   uint32
 	RDS                RDSInfo
+	LinkedServices     []LinkedService
 	SignalQuality      int
 	DeviationMaxHold   uint32
 	AM                 byte
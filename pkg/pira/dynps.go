@@ -0,0 +1,142 @@
+package pira
+
+import (
+	"strings"
+	"time"
+)
+
+// PSFrame is a PS value shown by the station starting at Time
+type PSFrame struct {
+	Time time.Time `json:"time"`
+	PS   string    `json:"ps"`
+}
+
+// PSReport describes the PS behaviour seen by a PSTracker
+type PSReport struct {
+	Samples     int           `json:"samples"`
+	Dynamic     bool          `json:"dynamic"`
+	Frames      []string      `json:"frames"`
+	Message     string        `json:"message"`
+	CyclePeriod time.Duration `json:"cycle_period"`
+}
+
+// PSTracker detects dynamic (scrolling) PS from frequently sampled PS
+// values. Reading the analyzer memory can catch a PS half way through an
+// update, so a value only counts as a frame once it is read MinRepeat
+// times in a row.
+type PSTracker struct {
+	MinRepeat int
+
+	samples int
+	pending string
+	repeat  int
+	since   time.Time
+	frames  []PSFrame
+}
+
+// NewPSTracker returns a tracker requiring two identical reads per frame
+func NewPSTracker() *PSTracker {
+	return &PSTracker{MinRepeat: 2}
+}
+
+// Add adds a PS read at time at
+func (t *PSTracker) Add(at time.Time, ps string) {
+	t.samples++
+	if ps != t.pending {
+		t.pending, t.repeat, t.since = ps, 0, at
+	}
+	t.repeat++
+	if t.repeat != max(t.MinRepeat, 1) {
+		return
+	}
+	if n := len(t.frames); n > 0 && t.frames[n-1].PS == ps {
+		return
+	}
+	t.frames = append(t.frames, PSFrame{Time: t.since, PS: ps})
+}
+
+// Frames returns every frame change seen so far
+func (t *PSTracker) Frames() []PSFrame {
+	return t.frames
+}
+
+// Report summarizes the frames seen so far. Frames holds one cycle of the
+// sequence when it repeats, Message is the text reconstructed from it.
+func (t *PSTracker) Report() PSReport {
+	report := PSReport{Samples: t.samples}
+	if len(t.frames) == 0 {
+		return report
+	}
+	sequence := make([]string, len(t.frames))
+	for i, f := range t.frames {
+		sequence[i] = f.PS
+	}
+
+	period := cyclePeriod(sequence)
+	if period > 0 {
+		repeats := (len(sequence) - 1) / period
+		elapsed := t.frames[repeats*period].Time.Sub(t.frames[0].Time)
+		report.CyclePeriod = elapsed / time.Duration(repeats)
+		sequence = sequence[:period]
+	}
+
+	distinct := map[string]bool{}
+	for _, ps := range sequence {
+		distinct[ps] = true
+	}
+	report.Dynamic = len(distinct) > 1
+	report.Frames = sequence
+	report.Message = reconstructMessage(sequence)
+	return report
+}
+
+// cyclePeriod returns the shortest period with which the sequence repeats
+// at least once, zero when it does not repeat
+func cyclePeriod(sequence []string) int {
+	for p := 1; 2*p <= len(sequence); p++ {
+		repeats := true
+		for i := 0; i+p < len(sequence); i++ {
+			if sequence[i] != sequence[i+p] {
+				repeats = false
+				break
+			}
+		}
+		if repeats {
+			return p
+		}
+	}
+	return 0
+}
+
+// reconstructMessage joins the frames of a scrolling PS, overlapping frames
+// are merged and frames showing separate words are joined with spaces
+func reconstructMessage(frames []string) string {
+	var b strings.Builder
+	last := ""
+	for _, frame := range frames {
+		if last == "" {
+			b.WriteString(strings.TrimSpace(frame))
+			last = frame
+			continue
+		}
+		if overlap := frameOverlap(last, frame); overlap > 0 {
+			b.WriteString(strings.TrimRight(frame[overlap:], " "))
+		} else {
+			b.WriteString(" ")
+			b.WriteString(strings.TrimSpace(frame))
+		}
+		last = frame
+	}
+	return b.String()
+}
+
+// frameOverlap returns the length of the longest suffix of a that is a
+// prefix of b and contains more than padding
+func frameOverlap(a, b string) int {
+	for n := min(len(a), len(b)) - 1; n > 0; n-- {
+		if a[len(a)-n:] == b[:n] && strings.TrimSpace(b[:n]) != "" {
+			return n
+		}
+	}
+	return 0
+}
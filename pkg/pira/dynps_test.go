@@ -0,0 +1,82 @@
+package pira
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPSTracker_Report(t *testing.T) {
+	tests := []struct {
+		name        string
+		frames      []string
+		wantDynamic bool
+		wantFrames  []string
+		wantMessage string
+		wantPeriod  time.Duration
+	}{
+		{
+			name:        "static",
+			frames:      []string{"RADIO 1 ", "RADIO 1 "},
+			wantDynamic: false,
+			wantFrames:  []string{"RADIO 1 "},
+			wantMessage: "RADIO 1",
+		},
+		{
+			name:        "word by word",
+			frames:      []string{" RADIO  ", "  ONE   ", "  NEWS  ", " RADIO  ", "  ONE   ", "  NEWS  ", " RADIO  "},
+			wantDynamic: true,
+			wantFrames:  []string{" RADIO  ", "  ONE   ", "  NEWS  "},
+			wantMessage: "RADIO ONE NEWS",
+			wantPeriod:  3 * time.Second,
+		},
+		{
+			name:        "scrolling",
+			frames:      []string{"HELLO WO", "LLO WORL", "O WORLD ", "HELLO WO", "LLO WORL", "O WORLD "},
+			wantDynamic: true,
+			wantFrames:  []string{"HELLO WO", "LLO WORL", "O WORLD "},
+			wantMessage: "HELLO WORLD",
+			wantPeriod:  3 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+			tracker := NewPSTracker()
+			at := start
+			for _, frame := range tt.frames {
+				// every frame is sampled four times a second
+				for range 4 {
+					tracker.Add(at, frame)
+					at = at.Add(250 * time.Millisecond)
+				}
+			}
+
+			report := tracker.Report()
+			if report.Dynamic != tt.wantDynamic {
+				t.Errorf("Report().Dynamic = %v, want %v", report.Dynamic, tt.wantDynamic)
+			}
+			if !reflect.DeepEqual(report.Frames, tt.wantFrames) {
+				t.Errorf("Report().Frames = %q, want %q", report.Frames, tt.wantFrames)
+			}
+			if report.Message != tt.wantMessage {
+				t.Errorf("Report().Message = %q, want %q", report.Message, tt.wantMessage)
+			}
+			if report.CyclePeriod != tt.wantPeriod {
+				t.Errorf("Report().CyclePeriod = %v, want %v", report.CyclePeriod, tt.wantPeriod)
+			}
+		})
+	}
+}
+
+func TestPSTracker_IgnoresTornReads(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracker := NewPSTracker()
+	for i, ps := range []string{"RADIO 1 ", "RADIO 1 ", "RADNEWS ", "RADIO 1 ", "RADIO 1 "} {
+		tracker.Add(start.Add(time.Duration(i)*100*time.Millisecond), ps)
+	}
+	if report := tracker.Report(); report.Dynamic || len(tracker.Frames()) != 1 {
+		t.Errorf("Report() = %+v, want a single static frame", report)
+	}
+}
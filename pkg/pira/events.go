@@ -0,0 +1,225 @@
+package pira
+
+import (
+	"fmt"
+	"time"
+)
+
+// EventType is the kind of change reported by a Differ
+type EventType string
+
+const (
+	EventFrequency      EventType = "frequency"
+	EventStereoLost     EventType = "stereo_lost"
+	EventStereoRestored EventType = "stereo_restored"
+	EventRDSLost        EventType = "rds_lost"
+	EventRDSRestored    EventType = "rds_restored"
+	EventPI             EventType = "pi"
+	EventPS             EventType = "ps"
+	EventPTY            EventType = "pty"
+	EventRT             EventType = "rt"
+	EventTA             EventType = "ta"
+	EventTP             EventType = "tp"
+	EventProgramme      EventType = "programme"
+)
+
+// Event is a change between successive readings
+type Event struct {
+	Type  EventType `json:"type"`
+	Field string    `json:"field"`
+	Old   any       `json:"old"`
+	New   any       `json:"new"`
+	Time  time.Time `json:"time"`
+}
+
+func (e Event) String() string {
+	return fmt.Sprintf("%s %s: %v -> %v", e.Time.Format(time.RFC3339), e.Field, e.Old, e.New)
+}
+
+// Default thresholds of the stereo and RDS presence, in Hz
+const (
+	DefaultPilotThreshold = 3000
+	DefaultRDSThreshold   = 1000
+)
+
+// DifferConfig tunes a Differ
+type DifferConfig struct {
+	// Debounce is the number of consecutive reads a new value must be
+	// seen before it is reported, default 2
+	Debounce int
+	// PilotThreshold is the pilot deviation in Hz below which the signal
+	// is considered mono, default 3000
+	PilotThreshold uint32
+	// RDSThreshold is the RDS deviation in Hz below which RDS is
+	// considered lost, default 1000
+	RDSThreshold uint32
+}
+
+// debounced holds a value that only changes after a new value has been
+// seen n times in a row
+type debounced[T comparable] struct {
+	value     T
+	candidate T
+	count     int
+	started   bool
+}
+
+// update adds a reading and returns the previous value when the stable
+// value changed. The first reading is taken as is.
+func (d *debounced[T]) update(v T, n int) (old T, changed bool) {
+	if !d.started {
+		d.value, d.started = v, true
+		return old, false
+	}
+	if v == d.value {
+		d.count = 0
+		return old, false
+	}
+	if d.count == 0 || v != d.candidate {
+		d.candidate, d.count = v, 0
+	}
+	d.count++
+	if d.count < n {
+		return old, false
+	}
+	old, d.value, d.count = d.value, v, 0
+	return old, true
+}
+
+// Differ turns successive FMInfo or BasicData readings into change
+// events. Only fields read by the analyzer are compared, RDS fields are
+// ignored while RDS is lost so the stale memory does not report changes.
+type Differ struct {
+	cfg DifferConfig
+
+	frequency      debounced[uint32]
+	basicFrequency debounced[float64]
+	stereo         debounced[bool]
+	rds            debounced[bool]
+	pi             debounced[PI]
+	ps             debounced[string]
+	pty            debounced[PTY]
+	rt             debounced[string]
+	ta             debounced[bool]
+	tp             debounced[bool]
+	pin            debounced[RDSPIN]
+	programme      PINTracker
+}
+
+// NewDiffer returns a Differ, zero config fields take their defaults
+func NewDiffer(cfg DifferConfig) *Differ {
+	if cfg.Debounce <= 0 {
+		cfg.Debounce = 2
+	}
+	if cfg.PilotThreshold == 0 {
+		cfg.PilotThreshold = DefaultPilotThreshold
+	}
+	if cfg.RDSThreshold == 0 {
+		cfg.RDSThreshold = DefaultRDSThreshold
+	}
+	return &Differ{cfg: cfg}
+}
+
+// Update compares fmi, read at time at, against the previous readings
+func (d *Differ) Update(at time.Time, fmi *FMInfo) []Event {
+	var events []Event
+	n := d.cfg.Debounce
+	if old, ok := d.frequency.update(fmi.Frequency, n); ok {
+		events = append(events, Event{Type: EventFrequency, Field: "frequency", Old: old, New: fmi.Frequency, Time: at})
+	}
+	events = d.updateStereo(events, at, fmi.PilotDeviation >= d.cfg.PilotThreshold)
+	events = d.updateRDS(events, at, fmi.RDSDeviation >= d.cfg.RDSThreshold)
+	if !d.rds.value {
+		return events
+	}
+
+	rds := &fmi.RDS
+	if old, ok := d.pi.update(rds.PI, n); ok {
+		events = append(events, Event{Type: EventPI, Field: "rds.pi", Old: old, New: rds.PI, Time: at})
+	}
+	if old, ok := d.ps.update(rds.PS, n); ok {
+		events = append(events, Event{Type: EventPS, Field: "rds.ps", Old: old, New: rds.PS, Time: at})
+	}
+	if old, ok := d.pty.update(rds.PTY, n); ok {
+		events = append(events, Event{Type: EventPTY, Field: "rds.pty", Old: old, New: rds.PTY, Time: at})
+	}
+	rt := cleanText(rds.RT)
+	if old, ok := d.rt.update(rt, n); ok {
+		events = append(events, Event{Type: EventRT, Field: "rds.rt", Old: old, New: rt, Time: at})
+	}
+	if old, ok := d.ta.update(rds.Status.TA, n); ok {
+		events = append(events, Event{Type: EventTA, Field: "rds.status.ta", Old: old, New: rds.Status.TA, Time: at})
+	}
+	if old, ok := d.tp.update(rds.Status.TP, n); ok {
+		events = append(events, Event{Type: EventTP, Field: "rds.status.tp", Old: old, New: rds.Status.TP, Time: at})
+	}
+	// the debounced PIN feeds the programme boundary tracker
+	d.pin.update(rds.PIN, n)
+	if b, ok := d.programme.update(at, d.pin.value, rds.pinReference(at)); ok {
+		events = append(events, Event{Type: EventProgramme, Field: "rds.pin", Old: b.Previous, New: b.Current, Time: at})
+	}
+	return events
+}
+
+// UpdateBasicData compares bd, read at time at, against the previous
+// readings. BasicData has no RDS content, only the frequency, stereo and
+// RDS presence are compared. Pilot and RDS deviation are read in kHz.
+func (d *Differ) UpdateBasicData(at time.Time, bd *BasicData) []Event {
+	var events []Event
+	if old, ok := d.basicFrequency.update(bd.Frequency, d.cfg.Debounce); ok {
+		events = append(events, Event{Type: EventFrequency, Field: "frequency", Old: old, New: bd.Frequency, Time: at})
+	}
+	stereo := bd.Pilot.Valid && bd.Pilot.Value*1000 >= float64(d.cfg.PilotThreshold)
+	events = d.updateStereo(events, at, stereo)
+	rds := bd.RDSDeviation.Valid && bd.RDSDeviation.Value*1000 >= float64(d.cfg.RDSThreshold)
+	return d.updateRDS(events, at, rds)
+}
+
+func (d *Differ) updateStereo(events []Event, at time.Time, stereo bool) []Event {
+	old, ok := d.stereo.update(stereo, d.cfg.Debounce)
+	if !ok {
+		return events
+	}
+	t := EventStereoLost
+	if stereo {
+		t = EventStereoRestored
+	}
+	return append(events, Event{Type: t, Field: "stereo", Old: old, New: stereo, Time: at})
+}
+
+func (d *Differ) updateRDS(events []Event, at time.Time, rds bool) []Event {
+	old, ok := d.rds.update(rds, d.cfg.Debounce)
+	if !ok {
+		return events
+	}
+	t := EventRDSLost
+	if rds {
+		t = EventRDSRestored
+	}
+	return append(events, Event{Type: t, Field: "rds", Old: old, New: rds, Time: at})
+}
+
+// UpdateDeviations compares the stereo and RDS presence of fmi, read
+// at time at, against the previous readings. It is used for fast
+// snapshots, whose other fields are copies of the last full read.
+func (d *Differ) UpdateDeviations(at time.Time, fmi *FMInfo) []Event {
+	events := d.updateStereo(nil, at, fmi.PilotDeviation >= d.cfg.PilotThreshold)
+	return d.updateRDS(events, at, fmi.RDSDeviation >= d.cfg.RDSThreshold)
+}
+
+// Snapshot compares a monitor snapshot, failed reads are skipped. Fast
+// snapshots only compare the deviations, and BasicData is only compared
+// without FMInfo as it repeats the frequency, stereo and RDS presence.
+func (d *Differ) Snapshot(s Snapshot) []Event {
+	switch {
+	case s.Err != nil:
+		return nil
+	case s.FMInfo != nil && s.Kind == SnapshotFast:
+		return d.UpdateDeviations(s.Time, s.FMInfo)
+	case s.FMInfo != nil:
+		return d.Update(s.Time, s.FMInfo)
+	case s.BasicData != nil:
+		return d.UpdateBasicData(s.Time, s.BasicData)
+	}
+	return nil
+}
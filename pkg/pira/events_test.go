@@ -0,0 +1,199 @@
+package pira
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDebounced_Update(t *testing.T) {
+	var d debounced[int]
+	steps := []struct {
+		value   int
+		wantOld int
+		wantOK  bool
+	}{
+		{value: 1},
+		{value: 2},
+		{value: 1},
+		{value: 2},
+		{value: 2, wantOld: 1, wantOK: true},
+		{value: 3},
+		{value: 4},
+		{value: 4, wantOld: 2, wantOK: true},
+	}
+	for i, step := range steps {
+		old, ok := d.update(step.value, 2)
+		if old != step.wantOld || ok != step.wantOK {
+			t.Errorf("step %d: update(%d) = %v, %v, want %v, %v", i, step.value, old, ok, step.wantOld, step.wantOK)
+		}
+	}
+}
+
+func TestDiffer_Update(t *testing.T) {
+	base := FMInfo{
+		Frequency:      10000,
+		PilotDeviation: 6800,
+		RDSDeviation:   3000,
+		RDS: RDSInfo{
+			PI:     0x5201,
+			PS:     "RADIO 1 ",
+			RT:     "Hello\r",
+			Status: RDSStatus{TP: true},
+		},
+	}
+	ta := base
+	ta.RDS.Status.TA = true
+	mono := ta
+	mono.PilotDeviation = 0
+	lost := mono
+	lost.RDSDeviation = 0
+	lost.RDS.PS = "garbage "
+	glitch := base
+	glitch.RDS.PI = 0x1234
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		reads []FMInfo
+		want  []EventType
+	}{
+		{
+			name:  "steady",
+			reads: []FMInfo{base, base, base},
+		},
+		{
+			name:  "single corrupted read",
+			reads: []FMInfo{base, glitch, base, base},
+		},
+		{
+			name:  "ta on",
+			reads: []FMInfo{base, ta, ta},
+			want:  []EventType{EventTA},
+		},
+		{
+			name:  "stereo dropped",
+			reads: []FMInfo{ta, mono, mono},
+			want:  []EventType{EventStereoLost},
+		},
+		{
+			name:  "rds lost ignores stale content",
+			reads: []FMInfo{mono, lost, lost, lost},
+			want:  []EventType{EventRDSLost},
+		},
+		{
+			name:  "rds restored",
+			reads: []FMInfo{lost, mono, mono},
+			want:  []EventType{EventRDSRestored},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDiffer(DifferConfig{})
+			var got []EventType
+			for i := range tt.reads {
+				for _, e := range d.Update(start.Add(time.Duration(i)*time.Second), &tt.reads[i]) {
+					got = append(got, e.Type)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Differ.Update() events = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffer_Event(t *testing.T) {
+	a := FMInfo{RDSDeviation: 3000, RDS: RDSInfo{PI: 0x5201, PS: "RADIO 1 "}}
+	b := a
+	b.RDS.PS = "RADIO 2 "
+	at := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	d := NewDiffer(DifferConfig{Debounce: 1})
+	d.Update(at, &a)
+	events := d.Update(at, &b)
+	want := []Event{{Type: EventPS, Field: "rds.ps", Old: "RADIO 1 ", New: "RADIO 2 ", Time: at}}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("Differ.Update() = %v, want %v", events, want)
+	}
+}
+
+func TestDiffer_UpdateBasicData(t *testing.T) {
+	stereo := BasicData{Frequency: 100.0, Pilot: Nullable[float64]{Value: 6.8, Valid: true}, RDSDeviation: Nullable[float64]{Value: 3.5, Valid: true}}
+	retuned := stereo
+	retuned.Frequency = 101.5
+	retuned.Pilot = Nullable[float64]{}
+
+	d := NewDiffer(DifferConfig{Debounce: 1})
+	d.UpdateBasicData(time.Now(), &stereo)
+	var got []EventType
+	for _, e := range d.UpdateBasicData(time.Now(), &retuned) {
+		got = append(got, e.Type)
+	}
+	want := []EventType{EventFrequency, EventStereoLost}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Differ.UpdateBasicData() events = %v, want %v", got, want)
+	}
+}
+
+func TestDiffer_Snapshot(t *testing.T) {
+	good := FMInfo{PilotDeviation: 6800, RDSDeviation: 3000, RDS: RDSInfo{PI: 0xC201, PS: "RADIO 1 "}}
+	bad := good
+	bad.RDS.PI = 0xC2FF
+	at := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	bd := &BasicData{Frequency: 100.0}
+
+	d := NewDiffer(DifferConfig{Debounce: 2})
+	d.Snapshot(Snapshot{Time: at, FMInfo: &good, BasicData: bd})
+	// a corrupted slow read copied into a fast snapshot is seen once
+	snapshots := []Snapshot{
+		{Time: at.Add(time.Second), FMInfo: &bad, BasicData: bd},
+		{Time: at.Add(2 * time.Second), Kind: SnapshotFast, FMInfo: &bad},
+		{Time: at.Add(3 * time.Second), FMInfo: &good, BasicData: bd},
+	}
+	for _, s := range snapshots {
+		if events := d.Snapshot(s); len(events) != 0 {
+			t.Errorf("Differ.Snapshot(%v) = %v, want no events", s.Kind, events)
+		}
+	}
+
+	// fast snapshots still report the deviation derived presence
+	mono := good
+	mono.PilotDeviation = 0
+	var got []EventType
+	for range 2 {
+		for _, e := range d.Snapshot(Snapshot{Time: at, Kind: SnapshotFast, FMInfo: &mono}) {
+			got = append(got, e.Type)
+		}
+	}
+	if want := []EventType{EventStereoLost}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Differ.Snapshot() fast events = %v, want %v", got, want)
+	}
+}
+
+func TestDiffer_Programme(t *testing.T) {
+	at := time.Date(2024, 3, 15, 8, 0, 30, 0, time.UTC)
+	a := FMInfo{RDSDeviation: 3000, RDS: RDSInfo{PIN: RDSPIN{Day: 15, Hour: 7}}}
+	unset := a
+	unset.RDS.PIN = RDSPIN{}
+	b := a
+	b.RDS.PIN = RDSPIN{Day: 15, Hour: 8}
+
+	d := NewDiffer(DifferConfig{Debounce: 1})
+	d.Update(at, &a)
+	if events := d.Update(at, &unset); len(events) != 0 {
+		t.Errorf("unset PIN events = %v, want none", events)
+	}
+	events := d.Update(at, &b)
+	want := []Event{{
+		Type:  EventProgramme,
+		Field: "rds.pin",
+		Old:   ProgrammeItem{PIN: RDSPIN{Day: 15, Hour: 7}, Start: time.Date(2024, 3, 15, 7, 0, 0, 0, time.UTC)},
+		New:   ProgrammeItem{PIN: RDSPIN{Day: 15, Hour: 8}, Start: time.Date(2024, 3, 15, 8, 0, 0, 0, time.UTC)},
+		Time:  at,
+	}}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("Differ.Update() = %v, want %v", events, want)
+	}
+}
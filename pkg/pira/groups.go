@@ -0,0 +1,147 @@
+package pira
+
+import (
+	"fmt"
+	"time"
+)
+
+// RDSGroupName returns the group type label ("0A" .. "15B") of the
+// analyzer group counter at index i
+func RDSGroupName(i int) string {
+	version := 'A'
+	if i%2 == 1 {
+		version = 'B'
+	}
+	return fmt.Sprintf("%d%c", i/2, version)
+}
+
+// RDSGroupCount is the statistics of a single group type
+type RDSGroupCount struct {
+	Group   string  `json:"group"`
+	Count   uint32  `json:"count"`
+	Percent float64 `json:"percent"`
+	Rate    float64 `json:"rate"`
+}
+
+// RDSGroupStats maps the 32 analyzer group counters to group types. When
+// built from a previous read the counts are the groups received in between
+// (counters wrap at 256) and the rates are in groups per second, otherwise
+// the counts are the raw counter values.
+type RDSGroupStats struct {
+	Time            time.Time       `json:"time"`
+	Counters        [32]byte        `json:"counters"`
+	Interval        time.Duration   `json:"interval"`
+	Total           uint32          `json:"total"`
+	GroupsPerSecond float64         `json:"groups_per_second"`
+	Groups          []RDSGroupCount `json:"groups"`
+}
+
+// NewRDSGroupStats builds statistics from counters read at time at,
+// prev may be nil for the first read
+func NewRDSGroupStats(counters [32]byte, at time.Time, prev *RDSGroupStats) *RDSGroupStats {
+	stats := &RDSGroupStats{
+		Time:     at,
+		Counters: counters,
+		Groups:   make([]RDSGroupCount, len(counters)),
+	}
+	if prev != nil && at.After(prev.Time) {
+		stats.Interval = at.Sub(prev.Time)
+	}
+	for i, c := range counters {
+		count := uint32(c)
+		if stats.Interval > 0 {
+			count = uint32(c - prev.Counters[i])
+		}
+		stats.Groups[i] = RDSGroupCount{Group: RDSGroupName(i), Count: count}
+		stats.Total += count
+	}
+	seconds := stats.Interval.Seconds()
+	for i := range stats.Groups {
+		g := &stats.Groups[i]
+		if stats.Total > 0 {
+			g.Percent = float64(g.Count) * 100 / float64(stats.Total)
+		}
+		if seconds > 0 {
+			g.Rate = float64(g.Count) / seconds
+		}
+	}
+	if seconds > 0 {
+		stats.GroupsPerSecond = float64(stats.Total) / seconds
+	}
+	return stats
+}
+
+// Group returns the statistics of the group type label, e.g. "2A"
+func (s *RDSGroupStats) Group(group string) (RDSGroupCount, bool) {
+	for _, g := range s.Groups {
+		if g.Group == group {
+			return g, true
+		}
+	}
+	return RDSGroupCount{}, false
+}
+
+// Data converts the statistics into the form returned by the ?B command,
+// group types that were not received are left out
+func (s *RDSGroupStats) Data() RDSGroupStatsData {
+	data := make(RDSGroupStatsData, 0, len(s.Groups))
+	for _, g := range s.Groups {
+		if g.Count > 0 {
+			data = append(data, RDSGroupStatsDataItem{Group: g.Group, Percent: g.Percent})
+		}
+	}
+	return data
+}
+
+// Percent returns the share of the group type label, zero if not listed
+func (d RDSGroupStatsData) Percent(group string) float64 {
+	for _, item := range d {
+		if item.Group == group {
+			return item.Percent
+		}
+	}
+	return 0
+}
+
+// RDSGroupStatsDiff compares the share of a group type computed from the
+// memory counters with the ?B statistics
+type RDSGroupStatsDiff struct {
+	Group      string  `json:"group"`
+	Counters   float64 `json:"counters"`
+	Statistics float64 `json:"statistics"`
+	Difference float64 `json:"difference"`
+}
+
+// Compare returns the per group difference against the ?B statistics for
+// every group type present in either
+func (s *RDSGroupStats) Compare(stats RDSGroupStatsData) []RDSGroupStatsDiff {
+	diffs := make([]RDSGroupStatsDiff, 0, len(s.Groups))
+	for _, g := range s.Groups {
+		percent := stats.Percent(g.Group)
+		if g.Percent == 0 && percent == 0 {
+			continue
+		}
+		diffs = append(diffs, RDSGroupStatsDiff{
+			Group:      g.Group,
+			Counters:   g.Percent,
+			Statistics: percent,
+			Difference: g.Percent - percent,
+		})
+	}
+	return diffs
+}
+
+// GroupStats builds group statistics from the RDS group counters
+func (r *RDSInfo) GroupStats(at time.Time, prev *RDSGroupStats) *RDSGroupStats {
+	return NewRDSGroupStats(r.Groups, at, prev)
+}
+
+// GetRDSGroupStats reads the group counters and builds statistics
+// relative to prev, which may be nil
+func (p *Pira) GetRDSGroupStats(prev *RDSGroupStats) (*RDSGroupStats, error) {
+	counters, err := p.GetRDSGroupCounters()
+	if err != nil {
+		return nil, err
+	}
+	return NewRDSGroupStats(counters, time.Now(), prev), nil
+}
@@ -0,0 +1,94 @@
+package pira
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRDSGroupName(t *testing.T) {
+	tests := []struct {
+		index int
+		want  string
+	}{
+		{index: 0, want: "0A"},
+		{index: 1, want: "0B"},
+		{index: 4, want: "2A"},
+		{index: 31, want: "15B"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := RDSGroupName(tt.index); got != tt.want {
+				t.Errorf("RDSGroupName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRDSGroupStats(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	var first, second [32]byte
+	first[0], first[4], first[8] = 250, 10, 1
+	// 0A wraps around from 250 to 4
+	second[0], second[4], second[8] = 4, 20, 1
+
+	prev := NewRDSGroupStats(first, start, nil)
+	if prev.Total != 261 || prev.GroupsPerSecond != 0 {
+		t.Fatalf("first read total = %v, rate = %v", prev.Total, prev.GroupsPerSecond)
+	}
+
+	stats := NewRDSGroupStats(second, start.Add(2*time.Second), prev)
+	tests := []struct {
+		group       string
+		wantCount   uint32
+		wantPercent float64
+		wantRate    float64
+	}{
+		{group: "0A", wantCount: 10, wantPercent: 50, wantRate: 5},
+		{group: "2A", wantCount: 10, wantPercent: 50, wantRate: 5},
+		{group: "4A", wantCount: 0, wantPercent: 0, wantRate: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.group, func(t *testing.T) {
+			got, ok := stats.Group(tt.group)
+			if !ok {
+				t.Fatalf("RDSGroupStats.Group() not found")
+			}
+			if got.Count != tt.wantCount || got.Percent != tt.wantPercent || got.Rate != tt.wantRate {
+				t.Errorf("RDSGroupStats.Group() = %+v, want count %v percent %v rate %v",
+					got, tt.wantCount, tt.wantPercent, tt.wantRate)
+			}
+		})
+	}
+	if stats.GroupsPerSecond != 10 {
+		t.Errorf("RDSGroupStats.GroupsPerSecond = %v, want 10", stats.GroupsPerSecond)
+	}
+}
+
+func TestRDSGroupStats_Compare(t *testing.T) {
+	var counters [32]byte
+	counters[0], counters[4] = 3, 1
+	stats := NewRDSGroupStats(counters, time.Now(), nil)
+	data := RDSGroupStatsData{
+		{Group: "0A", Percent: 70},
+		{Group: "2A", Percent: 25},
+		{Group: "4A", Percent: 5},
+	}
+
+	diffs := stats.Compare(data)
+	want := map[string]float64{"0A": 5, "2A": 0, "4A": -5}
+	if len(diffs) != len(want) {
+		t.Fatalf("RDSGroupStats.Compare() = %v, want %d groups", diffs, len(want))
+	}
+	for _, d := range diffs {
+		if math.Abs(d.Difference-want[d.Group]) > 1e-9 {
+			t.Errorf("RDSGroupStats.Compare() %s difference = %v, want %v", d.Group, d.Difference, want[d.Group])
+		}
+	}
+
+	if got := stats.Data(); len(got) != 2 || got[0].Group != "0A" || got[0].Percent != 75 {
+		t.Errorf("RDSGroupStats.Data() = %v", got)
+	}
+}
@@ -0,0 +1,88 @@
+package pira
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestLIC_Language(t *testing.T) {
+	tests := []struct {
+		name     string
+		lic      LIC
+		wantCode string
+		wantName string
+		wantOk   bool
+	}{
+		{name: "croatian", lic: 0x04, wantCode: "hr", wantName: "Croatian", wantOk: true},
+		{name: "english", lic: 0x09, wantCode: "en", wantName: "English", wantOk: true},
+		{name: "ruthenian", lic: 0x55, wantCode: "rue", wantName: "Ruthenian", wantOk: true},
+		{name: "papiamento", lic: 0x5B, wantCode: "pap", wantName: "Papiamento", wantOk: true},
+		{name: "dari", lic: 0x73, wantCode: "prs", wantName: "Dari", wantOk: true},
+		{name: "arabic", lic: 0x7E, wantCode: "ar", wantName: "Arabic", wantOk: true},
+		{name: "unknown", lic: 0x00, wantOk: false},
+		{name: "unassigned", lic: 0x30, wantOk: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.lic.Language()
+			if ok != tt.wantOk {
+				t.Errorf("LIC.Language() ok = %v, want %v", ok, tt.wantOk)
+				return
+			}
+			if got.Code != tt.wantCode || got.Name != tt.wantName {
+				t.Errorf("LIC.Language() = %+v, want %v %v", got, tt.wantCode, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestLIC_JSON(t *testing.T) {
+	data, err := json.Marshal(LIC(0x04))
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if want := `{"code":4,"iso":"hr","name":"Croatian"}`; string(data) != want {
+		t.Errorf("json.Marshal() = %v, want %v", string(data), want)
+	}
+
+	for _, input := range []string{string(data), "4"} {
+		var got LIC
+		if err := json.Unmarshal([]byte(input), &got); err != nil {
+			t.Fatalf("json.Unmarshal(%s) error = %v", input, err)
+		}
+		if got != 0x04 {
+			t.Errorf("json.Unmarshal(%s) = %v, want 04", input, got)
+		}
+	}
+}
+
+func TestECC_Country(t *testing.T) {
+	tests := []struct {
+		name   string
+		ecc    ECC
+		pi     PI
+		want   Country
+		wantOk bool
+	}{
+		{name: "croatia", ecc: 0xE3, pi: 0xC201, want: Country{Code: "HR", Name: "Croatia"}, wantOk: true},
+		{name: "united kingdom", ecc: 0xE1, pi: 0xC204, want: Country{Code: "GB", Name: "United Kingdom"}, wantOk: true},
+		{name: "germany shares nibble D with E0", ecc: 0xE0, pi: 0xD313, want: Country{Code: "DE", Name: "Germany"}, wantOk: true},
+		{name: "no ecc", ecc: 0x00, pi: 0xC201, wantOk: false},
+		{name: "unassigned nibble", ecc: 0xE0, pi: 0xE201, wantOk: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := RDSInfo{PI: tt.pi, ECC: tt.ecc}
+			got, ok := info.Country()
+			if ok != tt.wantOk {
+				t.Errorf("RDSInfo.Country() ok = %v, want %v", ok, tt.wantOk)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("RDSInfo.Country() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
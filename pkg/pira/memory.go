@@ -44,7 +44,7 @@ type MemoryPart1 struct {
 	U8                       byte      //0x1E5
 	RDSCTMinute              byte      //0x1E6
 	U9                       [3]byte   //0x1E7
-	RDSMJD                   [3]byte   //0x1EA
+	RDSMJD                   MJD       //0x1EA
 	U10                      byte      //0x1ED
 	RDSRTPlusGroupType       byte      //0x1EE
 	RDSRTPlusStatus          byte      //0x1EF
@@ -78,6 +78,8 @@ func (p *Pira) Load(addr int, structure any) error {
 	if addr > 0xFFF || size > 0xFFF {
 		panic("invalid address or size")
 	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
 
 	command := fmt.Sprintf("%03X,%03X?h", addr, size)
 	n, err := p.SendCommand(Command(command))
@@ -151,7 +153,7 @@ func parseRDSStatus(rdsStatus uint16) (status *RDSStatus) {
 		TP:     rdsStatus&0b00001000000 != 0,
 		TA:     rdsStatus&0b00000100000 != 0,
 		MS:     rdsStatus&0b00000010000 != 0,
-		DI:     byte(rdsStatus & 0b00000001111),
+		DI:     DI(rdsStatus & 0b00000001111),
 	}
 	return status
 }
@@ -204,8 +206,8 @@ func (p *Pira) GetModulationPower() (float64, error) {
 	return parseModulationPower(mp), nil
 }
 
-func (p *Pira) GetRDSPI() (uint16, error) {
-	var rdsPI uint16
+func (p *Pira) GetRDSPI() (PI, error) {
+	var rdsPI PI
 	err := p.Load(0x032, &rdsPI)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get rds pi: %w", err)
@@ -222,13 +224,13 @@ func (p *Pira) GetRDSPS() (string, error) {
 	return string(rdsPS[:]), nil
 }
 
-func (p *Pira) GetRDSPTY() (byte, error) {
+func (p *Pira) GetRDSPTY() (PTY, error) {
 	var rdsPTY byte
 	err := p.Load(0x03C, &rdsPTY)
 	if err != nil {
-		return 0, fmt.Errorf("failed to get rds pty: %w", err)
+		return PTY{}, fmt.Errorf("failed to get rds pty: %w", err)
 	}
-	return rdsPTY, nil
+	return PTY{Code: rdsPTY, Region: p.Region()}, nil
 }
 
 func (p *Pira) GetRDSStatus() (*RDSStatus, error) {
@@ -258,11 +260,11 @@ func (p *Pira) GetRDSAFList() ([26]byte, error) {
 	return rdsAFList, nil
 }
 
-func (p *Pira) GetRDSEONPI() ([4]uint16, error) {
-	var rdsEONPI [4]uint16
+func (p *Pira) GetRDSEONPI() ([4]PI, error) {
+	var rdsEONPI [4]PI
 	err := p.Load(0x07A, &rdsEONPI)
 	if err != nil {
-		return [4]uint16{}, fmt.Errorf("failed to get rds eonpi: %w", err)
+		return [4]PI{}, fmt.Errorf("failed to get rds eonpi: %w", err)
 	}
 	return rdsEONPI, nil
 }
@@ -314,7 +316,7 @@ func (p *Pira) GetRDSPTYN() (string, error) {
 
 func (p *Pira) GetRDSCT() (*RDSCT, error) {
 	var (
-		data   [3]byte
+		data   [9]byte
 		offset byte
 		err    error
 	)
@@ -330,15 +332,16 @@ func (p *Pira) GetRDSCT() (*RDSCT, error) {
 		Hour:            data[0],
 		Minute:          data[2],
 		LocalTimeOffset: offset,
+		MJD:             MJD(data[6:9]),
 	}
 	return rdsCT, nil
 }
 
-func (p *Pira) GetRDSMJD() ([3]byte, error) {
-	var rdsMJD [3]byte
+func (p *Pira) GetRDSMJD() (MJD, error) {
+	var rdsMJD MJD
 	err := p.Load(0x1EA, &rdsMJD)
 	if err != nil {
-		return [3]byte{}, fmt.Errorf("failed to get rds mjd: %w", err)
+		return MJD{}, fmt.Errorf("failed to get rds mjd: %w", err)
 	}
 	return rdsMJD, nil
 }
@@ -361,8 +364,8 @@ func (p *Pira) GetRDSPIN() (*RDSPIN, error) {
 	return &rdsPIN, nil
 }
 
-func (p *Pira) GetRDSLIC() (byte, error) {
-	var rdsLIC byte
+func (p *Pira) GetRDSLIC() (LIC, error) {
+	var rdsLIC LIC
 	err := p.Load(0x1FB, &rdsLIC)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get rds lic: %w", err)
@@ -370,8 +373,8 @@ func (p *Pira) GetRDSLIC() (byte, error) {
 	return rdsLIC, nil
 }
 
-func (p *Pira) GetRDSECC() (byte, error) {
-	var rdsECC byte
+func (p *Pira) GetRDSECC() (ECC, error) {
+	var rdsECC ECC
 	err := p.Load(0x1FC, &rdsECC)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get rds ecc: %w", err)
@@ -406,6 +409,41 @@ func (p *Pira) GetRDSLongPS() (string, error) {
 	return string(rdsLongPS[:]), nil
 }
 
+// deviationMemory is the block of measurements polled at a high rate
+type deviationMemory struct {
+	PilotDeviation           uint16 // 0x024
+	RDSDeviation             uint16 // 0x026
+	PiotToRDSPhaseDifference int16  // 0x028
+	DeviationMax             uint16 // 0x02A
+	DeviationAverage         uint16 // 0x02C
+	ModulationPower          uint16 // 0x02E
+	DeviationMinHold         uint16 // 0x030
+}
+
+// GetDeviations updates only the fast changing deviation, phase and
+// modulation power fields of fmi, it takes two reads instead of the
+// two large blocks read by GetFMInfo
+func (p *Pira) GetDeviations(fmi *FMInfo) error {
+	var mem deviationMemory
+	err := p.Load(0x024, &mem)
+	if err != nil {
+		return fmt.Errorf("failed to get deviations: %w", err)
+	}
+	deviation, err := p.GetDeviation(Deviation)
+	if err != nil {
+		return err
+	}
+	fmi.PilotDeviation = parseDeviation(mem.PilotDeviation)
+	fmi.RDSDeviation = parseDeviation(mem.RDSDeviation)
+	fmi.RDSPhaseDifference = parsePiotToRDSPhaseDifference(mem.PiotToRDSPhaseDifference)
+	fmi.DeviationMax = parseDeviation(mem.DeviationMax)
+	fmi.DeviationAverage = parseDeviation(mem.DeviationAverage)
+	fmi.ModulationPower = parseModulationPower(mem.ModulationPower)
+	fmi.DeviationMinHold = parseDeviation(mem.DeviationMinHold)
+	fmi.Deviation = deviation
+	return nil
+}
+
 func (p *Pira) GetFMInfo(fmi *FMInfo) (err error) {
 	mem1 := MemoryPart1{}
 	err = p.Load(0x01A, &mem1)
@@ -427,20 +465,26 @@ func (p *Pira) GetFMInfo(fmi *FMInfo) (err error) {
 	fmi.ModulationPower = parseModulationPower(mem1.ModulationPower)
 	fmi.DeviationMinHold = parseDeviation(mem1.DeviationMinHold)
 
+	fmi.RDS.PI = PI(mem1.RDSPI)
+	fmi.RDS.PIInfo = fmi.RDS.PI.Decode(ECC(mem1.RDSECC), p.Region())
+	fmi.RDS.PS = string(mem1.RDSPS[:])
+	fmi.RDS.PTY = PTY{Code: mem1.RDSPTY, Region: p.Region()}
 	fmi.RDS.Status = *parseRDSStatus(mem1.RDSStatus)
 	fmi.RDS.Groups = mem1.RDSGroupCounters
-	fmi.RDS.EONPI = mem1.RDSEONPI
+	for i, pi := range mem1.RDSEONPI {
+		fmi.RDS.EONPI[i] = PI(pi)
+	}
 	fmi.RDS.RT = string(mem1.RDSRT[:])
 	fmi.RDS.PTYN = string(mem1.RDSPTYN[:])
 	fmi.RDS.CT.Hour = mem1.RDSCTHour
 	fmi.RDS.CT.Minute = mem1.RDSCTMinute
 	fmi.RDS.CT.LocalTimeOffset = mem1.RDSCTLocalTimeOffset
-	fmi.RDS.MJD = mem1.RDSMJD
+	fmi.RDS.CT.MJD = mem1.RDSMJD
 	fmi.RDS.PIN.Day = mem1.RDSPINDay
 	fmi.RDS.PIN.Hour = mem1.RDSPINHour
 	fmi.RDS.PIN.Minute = mem1.RDSPINMinute
-	fmi.RDS.LIC = mem1.RDSLIC
-	fmi.RDS.ECC = mem1.RDSECC
+	fmi.RDS.LIC = LIC(mem1.RDSLIC)
+	fmi.RDS.ECC = ECC(mem1.RDSECC)
 
 	fmi.RDS.RTPlus.GroupType = mem1.RDSRTPlusGroupType
 	fmi.RDS.RTPlus.Status = mem1.RDSRTPlusStatus
@@ -452,6 +496,7 @@ func (p *Pira) GetFMInfo(fmi *FMInfo) (err error) {
 	fmi.RDS.RTPlus.Item2.Length = mem1.RDSRTPlusItem2Length
 
 	fmi.RDS.LongPS = string(mem2.RDSLongPS[:])
+	fmi.LinkedServices = fmi.RDS.LinkedServices(p.stationDB(), p.Region())
 
 	fmi.SignalQuality = int(mem1.SignalQuality)
 	fmi.DeviationMaxHold = parseDeviation(mem1.DeviationMaxHold)
@@ -0,0 +1,64 @@
+package pira
+
+// FrequencyStep is the unit of FMInfo.Frequency in Hz, frequencies are
+// read in kHz
+const FrequencyStep = 1000
+
+// Metric names shared by FMInfo and BasicData. The frequency and
+// deviations are in Hz, modulation power in dBr.
+const (
+	MetricFrequency          = "frequency"
+	MetricPilotDeviation     = "pilot_deviation"
+	MetricRDSDeviation       = "rds_deviation"
+	MetricRDSPhaseDifference = "rds_phase_difference"
+	MetricDeviation          = "deviation"
+	MetricDeviationMax       = "deviation_max"
+	MetricDeviationAverage   = "deviation_average"
+	MetricDeviationMinHold   = "deviation_min_hold"
+	MetricDeviationMaxHold   = "deviation_max_hold"
+	MetricModulationPower    = "modulation_power"
+	MetricSignalQuality      = "signal_quality"
+	MetricNoiseLevel         = "noise_level"
+)
+
+// Metrics returns the numeric measurements of fmi by stable name
+func (fmi *FMInfo) Metrics() map[string]float64 {
+	return map[string]float64{
+		MetricFrequency:          float64(fmi.Frequency) * FrequencyStep,
+		MetricPilotDeviation:     float64(fmi.PilotDeviation),
+		MetricRDSDeviation:       float64(fmi.RDSDeviation),
+		MetricRDSPhaseDifference: float64(fmi.RDSPhaseDifference),
+		MetricDeviation:          float64(fmi.Deviation),
+		MetricDeviationMax:       float64(fmi.DeviationMax),
+		MetricDeviationAverage:   float64(fmi.DeviationAverage),
+		MetricDeviationMinHold:   float64(fmi.DeviationMinHold),
+		MetricDeviationMaxHold:   float64(fmi.DeviationMaxHold),
+		MetricModulationPower:    fmi.ModulationPower,
+		MetricSignalQuality:      float64(fmi.SignalQuality),
+		MetricNoiseLevel:         float64(fmi.NoiseLevel),
+	}
+}
+
+// Metrics returns the measurements of bd by the same names as
+// FMInfo.Metrics, readings the analyzer reported as unavailable are
+// left out. The frequency is read in MHz and deviations in kHz, both are
+// converted to Hz.
+func (bd *BasicData) Metrics() map[string]float64 {
+	metrics := map[string]float64{
+		MetricFrequency:     bd.Frequency * 1e6,
+		MetricSignalQuality: float64(bd.SignalQuality),
+	}
+	if bd.Pilot.Valid {
+		metrics[MetricPilotDeviation] = bd.Pilot.Value * 1000
+	}
+	if bd.RDSDeviation.Valid {
+		metrics[MetricRDSDeviation] = bd.RDSDeviation.Value * 1000
+	}
+	if bd.RDSPhaseDifference.Valid {
+		metrics[MetricRDSPhaseDifference] = bd.RDSPhaseDifference.Value
+	}
+	if bd.ModulationPower.Valid {
+		metrics[MetricModulationPower] = bd.ModulationPower.Value
+	}
+	return metrics
+}
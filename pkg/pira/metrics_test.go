@@ -0,0 +1,38 @@
+package pira
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFMInfo_Metrics(t *testing.T) {
+	fmi := FMInfo{Frequency: 100500, PilotDeviation: 6800, RDSPhaseDifference: -3, DeviationMax: 75000, SignalQuality: 90}
+	got := fmi.Metrics()
+	for name, want := range map[string]float64{
+		MetricFrequency:          100_500_000,
+		MetricPilotDeviation:     6800,
+		MetricRDSPhaseDifference: -3,
+		MetricDeviationMax:       75000,
+		MetricSignalQuality:      90,
+	} {
+		if got[name] != want {
+			t.Errorf("FMInfo.Metrics()[%q] = %v, want %v", name, got[name], want)
+		}
+	}
+}
+
+func TestBasicData_Metrics(t *testing.T) {
+	bd := BasicData{
+		Frequency:     100.5,
+		SignalQuality: 80,
+		Pilot:         Nullable[float64]{Value: 6.8, Valid: true},
+	}
+	want := map[string]float64{
+		MetricFrequency:      100_500_000,
+		MetricSignalQuality:  80,
+		MetricPilotDeviation: 6800,
+	}
+	if got := bd.Metrics(); !reflect.DeepEqual(got, want) {
+		t.Errorf("BasicData.Metrics() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,306 @@
+package pira
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"sync"
+	"time"
+)
+
+// Source is the part of the Pira client polled by a Monitor
+type Source interface {
+	GetFMInfo(fmi *FMInfo) error
+	GetDeviations(fmi *FMInfo) error
+	GetBasicData() (*BasicData, error)
+}
+
+// SnapshotKind tells which poll produced a snapshot
+type SnapshotKind int
+
+const (
+	// SnapshotSlow is a full FMInfo read, including RDS
+	SnapshotSlow SnapshotKind = iota
+	// SnapshotFast only refreshed the deviation fields, the other fields
+	// are those of the last slow snapshot
+	SnapshotFast
+)
+
+func (k SnapshotKind) String() string {
+	if k == SnapshotFast {
+		return "fast"
+	}
+	return "slow"
+}
+
+// MarshalText implements the encoding.TextMarshaler interface for SnapshotKind
+func (k SnapshotKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface for SnapshotKind
+func (k *SnapshotKind) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "slow":
+		*k = SnapshotSlow
+	case "fast":
+		*k = SnapshotFast
+	default:
+		return fmt.Errorf("unknown snapshot kind %q", text)
+	}
+	return nil
+}
+
+// Snapshot is a timestamped monitor reading. Time carries both the wall
+// clock and the monotonic clock reading, Monotonic is the monotonic time
+// since the monitor started. Err is the error of the FMInfo read and
+// BasicErr the error of the BasicData read, a failed BasicData read keeps
+// the FMInfo.
+type Snapshot struct {
+	Seq       uint64
+	Time      time.Time
+	Monotonic time.Duration
+	Kind      SnapshotKind
+	FMInfo    *FMInfo
+	BasicData *BasicData
+	Err       error
+	BasicErr  error
+}
+
+// snapshotJSON is the JSON form of a Snapshot
+type snapshotJSON struct {
+	Seq       uint64        `json:"seq"`
+	Time      time.Time     `json:"time"`
+	Monotonic time.Duration `json:"monotonic"`
+	Kind      SnapshotKind  `json:"kind"`
+	FMInfo    *FMInfo       `json:"fm_info,omitempty"`
+	BasicData *BasicData    `json:"basic_data,omitempty"`
+	Error     string        `json:"error,omitempty"`
+	BasicErr  string        `json:"basic_data_error,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface for Snapshot
+func (s Snapshot) MarshalJSON() ([]byte, error) {
+	v := snapshotJSON{
+		Seq:       s.Seq,
+		Time:      s.Time,
+		Monotonic: s.Monotonic,
+		Kind:      s.Kind,
+		FMInfo:    s.FMInfo,
+		BasicData: s.BasicData,
+	}
+	if s.Err != nil {
+		v.Error = s.Err.Error()
+	}
+	if s.BasicErr != nil {
+		v.BasicErr = s.BasicErr.Error()
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for Snapshot,
+// it reads recorded snapshots back. Errors only keep their message.
+func (s *Snapshot) UnmarshalJSON(data []byte) error {
+	var v snapshotJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*s = Snapshot{
+		Seq:       v.Seq,
+		Time:      v.Time,
+		Monotonic: v.Monotonic,
+		Kind:      v.Kind,
+		FMInfo:    v.FMInfo,
+		BasicData: v.BasicData,
+	}
+	if v.Error != "" {
+		s.Err = errors.New(v.Error)
+	}
+	if v.BasicErr != "" {
+		s.BasicErr = errors.New(v.BasicErr)
+	}
+	return nil
+}
+
+// MonitorConfig sets the poll rates of a Monitor
+type MonitorConfig struct {
+	// SlowInterval is the period of full FMInfo reads, RDS text included
+	SlowInterval time.Duration
+	// FastInterval is the period of deviation only reads, zero disables them
+	FastInterval time.Duration
+	// BasicData also reads BasicData on every slow poll
+	BasicData bool
+}
+
+// Monitor polls a Source and delivers snapshots to its subscribers. The
+// source is only read from the Run goroutine. Delivery never blocks the
+// poll loop: a subscriber that does not keep up misses snapshots, which
+// shows as gaps in Seq.
+type Monitor struct {
+	src Source
+	cfg MonitorConfig
+
+	mu      sync.Mutex
+	subs    map[int]chan Snapshot
+	nextID  int
+	stopped bool
+
+	seq   uint64
+	start time.Time
+	last  FMInfo
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewMonitor returns a monitor polling src, a zero SlowInterval defaults to one second
+func NewMonitor(src Source, cfg MonitorConfig) *Monitor {
+	if cfg.SlowInterval <= 0 {
+		cfg.SlowInterval = time.Second
+	}
+	return &Monitor{
+		src:  src,
+		cfg:  cfg,
+		subs: make(map[int]chan Snapshot),
+		done: make(chan struct{}),
+	}
+}
+
+// Subscribe returns a channel receiving snapshots with room for buffer
+// snapshots and a function to cancel the subscription. The channel is
+// closed on cancel and when the monitor stops.
+func (m *Monitor) Subscribe(buffer int) (<-chan Snapshot, func()) {
+	ch := make(chan Snapshot, buffer)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stopped {
+		close(ch)
+		return ch, func() {}
+	}
+	id := m.nextID
+	m.nextID++
+	m.subs[id] = ch
+	return ch, func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if sub, ok := m.subs[id]; ok {
+			delete(m.subs, id)
+			close(sub)
+		}
+	}
+}
+
+// Snapshots iterates over snapshots until the monitor stops, ctx is done
+// or the loop breaks. It subscribes when called, not when the iteration
+// starts, so calling it before Run does not miss the first snapshot. The
+// sequence can be iterated once.
+func (m *Monitor) Snapshots(ctx context.Context) iter.Seq[Snapshot] {
+	ch, cancel := m.Subscribe(16)
+	return func(yield func(Snapshot) bool) {
+		defer cancel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case s, ok := <-ch:
+				if !ok || !yield(s) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Run polls the source until ctx is done or Close is called, then closes
+// all subscriptions. A monitor can only be run once.
+func (m *Monitor) Run(ctx context.Context) error {
+	defer m.stop()
+	m.start = time.Now()
+
+	slow := time.NewTicker(m.cfg.SlowInterval)
+	defer slow.Stop()
+	var fast <-chan time.Time
+	if m.cfg.FastInterval > 0 {
+		ticker := time.NewTicker(m.cfg.FastInterval)
+		defer ticker.Stop()
+		fast = ticker.C
+	}
+
+	m.pollSlow()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-m.done:
+			return nil
+		case <-slow.C:
+			m.pollSlow()
+		case <-fast:
+			m.pollFast()
+		}
+	}
+}
+
+// Close stops a running monitor
+func (m *Monitor) Close() {
+	m.closeOnce.Do(func() { close(m.done) })
+}
+
+func (m *Monitor) stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stopped = true
+	for id, ch := range m.subs {
+		delete(m.subs, id)
+		close(ch)
+	}
+}
+
+func (m *Monitor) pollSlow() {
+	var (
+		fmi      FMInfo
+		bd       *BasicData
+		basicErr error
+	)
+	err := m.src.GetFMInfo(&fmi)
+	if err == nil {
+		m.last = fmi
+		if m.cfg.BasicData {
+			bd, basicErr = m.src.GetBasicData()
+		}
+	}
+	if basicErr != nil {
+		bd = nil
+	}
+	m.publish(Snapshot{Kind: SnapshotSlow, FMInfo: &fmi, BasicData: bd, Err: err, BasicErr: basicErr})
+}
+
+func (m *Monitor) pollFast() {
+	fmi := m.last
+	err := m.src.GetDeviations(&fmi)
+	if err == nil {
+		m.last = fmi
+	}
+	m.publish(Snapshot{Kind: SnapshotFast, FMInfo: &fmi, Err: err})
+}
+
+// publish stamps s and delivers it, the FMInfo of a failed read is dropped
+func (m *Monitor) publish(s Snapshot) {
+	now := time.Now()
+	m.seq++
+	s.Seq, s.Time, s.Monotonic = m.seq, now, now.Sub(m.start)
+	if s.Err != nil {
+		s.FMInfo = nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, ch := range m.subs {
+		select {
+		case ch <- s:
+		default:
+		}
+	}
+}
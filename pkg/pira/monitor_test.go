@@ -0,0 +1,173 @@
+package pira
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeSource struct {
+	mu         sync.Mutex
+	slow, fast int
+	err        error
+	basicErr   error
+}
+
+func (f *fakeSource) GetFMInfo(fmi *FMInfo) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.slow++
+	if f.err != nil {
+		return f.err
+	}
+	fmi.Frequency = 100000
+	fmi.RDS.PS = "RADIO 1 "
+	fmi.DeviationMax = 1000
+	return nil
+}
+
+func (f *fakeSource) GetDeviations(fmi *FMInfo) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fast++
+	fmi.DeviationMax = uint32(1000 + f.fast)
+	return nil
+}
+
+func (f *fakeSource) GetBasicData() (*BasicData, error) {
+	if f.basicErr != nil {
+		return nil, f.basicErr
+	}
+	return &BasicData{}, nil
+}
+
+func TestMonitor_Snapshots(t *testing.T) {
+	src := &fakeSource{}
+	m := NewMonitor(src, MonitorConfig{SlowInterval: 50 * time.Millisecond, FastInterval: 5 * time.Millisecond, BasicData: true})
+	ch, cancel := m.Subscribe(64)
+	defer cancel()
+
+	ctx, stop := context.WithTimeout(context.Background(), 120*time.Millisecond)
+	defer stop()
+	go m.Run(ctx)
+
+	var (
+		got      []Snapshot
+		lastSeq  uint64
+		lastTime time.Duration
+	)
+	for s := range ch {
+		if s.Seq <= lastSeq {
+			t.Errorf("Seq = %d after %d, want increasing", s.Seq, lastSeq)
+		}
+		if s.Monotonic < lastTime {
+			t.Errorf("Monotonic = %v after %v, want non decreasing", s.Monotonic, lastTime)
+		}
+		lastSeq, lastTime = s.Seq, s.Monotonic
+		got = append(got, s)
+	}
+
+	if len(got) == 0 {
+		t.Fatal("no snapshots received")
+	}
+	first := got[0]
+	if first.Kind != SnapshotSlow || first.BasicData == nil {
+		t.Errorf("first snapshot = %v with basic data %v, want slow with basic data", first.Kind, first.BasicData)
+	}
+	var fast int
+	for _, s := range got {
+		if s.Kind != SnapshotFast {
+			continue
+		}
+		fast++
+		if s.FMInfo.RDS.PS != "RADIO 1 " || s.FMInfo.Frequency != 100000 {
+			t.Errorf("fast snapshot lost slow fields: %+v", s.FMInfo)
+		}
+		if s.FMInfo.DeviationMax <= 1000 {
+			t.Errorf("fast snapshot DeviationMax = %d, want updated", s.FMInfo.DeviationMax)
+		}
+	}
+	if fast == 0 {
+		t.Error("no fast snapshots received")
+	}
+}
+
+func TestMonitor_Error(t *testing.T) {
+	src := &fakeSource{err: errors.New("timeout")}
+	m := NewMonitor(src, MonitorConfig{SlowInterval: 5 * time.Millisecond})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errc := make(chan error, 1)
+	go func() { errc <- m.Run(ctx) }()
+
+	var got Snapshot
+	for s := range m.Snapshots(ctx) {
+		got = s
+		m.Close()
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if got.Err == nil || got.FMInfo != nil {
+		t.Fatalf("snapshot = %+v, want error without FMInfo", got)
+	}
+	data, err := json.Marshal(got)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if !strings.Contains(string(data), `"error":"timeout"`) || !strings.Contains(string(data), `"kind":"slow"`) {
+		t.Errorf("json.Marshal() = %s", data)
+	}
+}
+
+func TestMonitor_BasicDataError(t *testing.T) {
+	src := &fakeSource{basicErr: errors.New("timeout")}
+	m := NewMonitor(src, MonitorConfig{SlowInterval: time.Hour, BasicData: true})
+	snapshots := m.Snapshots(context.Background())
+	go m.Run(context.Background())
+
+	for s := range snapshots {
+		m.Close()
+		if s.Err != nil || s.FMInfo == nil || s.FMInfo.Frequency != 100000 {
+			t.Errorf("snapshot = %+v, want FMInfo despite the BasicData error", s)
+		}
+		if s.BasicErr == nil || s.BasicData != nil {
+			t.Errorf("snapshot BasicErr = %v, BasicData = %v, want error", s.BasicErr, s.BasicData)
+		}
+	}
+}
+
+func TestMonitor_SnapshotsBeforeRun(t *testing.T) {
+	m := NewMonitor(&fakeSource{}, MonitorConfig{SlowInterval: time.Hour})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the first poll happens as soon as Run starts
+	snapshots := m.Snapshots(ctx)
+	go m.Run(ctx)
+	for s := range snapshots {
+		if s.Seq != 1 {
+			t.Errorf("first snapshot Seq = %d, want 1", s.Seq)
+		}
+		break
+	}
+}
+
+func TestMonitor_SubscribeAfterStop(t *testing.T) {
+	m := NewMonitor(&fakeSource{}, MonitorConfig{})
+	m.Close()
+	if err := m.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	ch, cancel := m.Subscribe(1)
+	defer cancel()
+	if _, ok := <-ch; ok {
+		t.Error("Subscribe() after stop returned an open channel")
+	}
+}
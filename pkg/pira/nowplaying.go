@@ -0,0 +1,94 @@
+package pira
+
+import (
+	"strings"
+	"time"
+)
+
+// NowPlayingEntry is an item of the now playing history
+type NowPlayingEntry struct {
+	Time   time.Time `json:"time"`
+	Artist string    `json:"artist"`
+	Title  string    `json:"title"`
+	Album  string    `json:"album"`
+	RT     string    `json:"rt"`
+}
+
+// NowPlayingTracker turns successive RT and RT+ readings into a now playing
+// history. A new item has to be read Confirm times in a row before it is
+// reported, which filters out RT+ tags applied to a half received RT, and
+// an item is not reported again until the RT+ toggle bit flips.
+type NowPlayingTracker struct {
+	Confirm int
+
+	last       string
+	lastToggle bool
+	started    bool
+
+	candidate string
+	count     int
+	since     time.Time
+}
+
+// NewNowPlayingTracker returns a tracker confirming items on the second reading
+func NewNowPlayingTracker() *NowPlayingTracker {
+	return &NowPlayingTracker{Confirm: 2}
+}
+
+// Update adds a reading taken at time at and returns the entry when a new
+// item has been confirmed
+func (t *NowPlayingTracker) Update(at time.Time, rt string, rtPlus RTPlus) (NowPlayingEntry, bool) {
+	if t.started && rtPlus.Running() && rtPlus.Toggle() != t.lastToggle {
+		// a new item started, the same song may be played again
+		t.last = ""
+	}
+	t.started = true
+	t.lastToggle = rtPlus.Toggle()
+
+	// RT+ positions index the raw RadioText, the tags are cleaned on
+	// extraction
+	np := rtPlus.NowPlaying(rt)
+	rt = cleanText(rt)
+	if np.IsEmpty() {
+		np = splitRadioText(rt)
+	}
+	if np.IsEmpty() {
+		t.candidate, t.count = "", 0
+		return NowPlayingEntry{}, false
+	}
+
+	key := np.Artist + "\x00" + np.Title
+	if key == t.last {
+		t.candidate, t.count = "", 0
+		return NowPlayingEntry{}, false
+	}
+	if key != t.candidate {
+		t.candidate, t.count, t.since = key, 0, at
+	}
+	t.count++
+	if t.count < max(t.Confirm, 1) {
+		return NowPlayingEntry{}, false
+	}
+
+	t.last = key
+	t.candidate, t.count = "", 0
+	return NowPlayingEntry{Time: t.since, Artist: np.Artist, Title: np.Title, Album: np.Album, RT: rt}, true
+}
+
+// splitRadioText falls back to the common "Artist - Title" RadioText
+// layout when RT+ is not available
+func splitRadioText(rt string) NowPlaying {
+	artist, title, ok := strings.Cut(rt, " - ")
+	if !ok {
+		return NowPlaying{}
+	}
+	return NowPlaying{Artist: strings.TrimSpace(artist), Title: strings.TrimSpace(title)}
+}
+
+// cleanText cuts RDS text at the carriage return terminator and trims the padding
+func cleanText(s string) string {
+	if cr := strings.IndexByte(s, '\r'); cr >= 0 {
+		s = s[:cr]
+	}
+	return strings.TrimSpace(strings.Trim(s, "\x00"))
+}
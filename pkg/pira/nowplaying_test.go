@@ -0,0 +1,72 @@
+package pira
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNowPlayingTracker_Update(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	queen := RTPlus{
+		Status: RTPlusStatusRunning,
+		Item1:  RTPlusItem{Type: 4, Start: 0, Length: 4},
+		Item2:  RTPlusItem{Type: 1, Start: 8, Length: 16},
+	}
+	queenAgain := queen
+	queenAgain.Status |= RTPlusStatusToggle
+
+	readings := []struct {
+		rt        string
+		rtPlus    RTPlus
+		wantEntry bool
+		wantTitle string
+	}{
+		{rt: "Queen - Bohemian Rhapsody\r", rtPlus: queen},
+		{rt: "Queen - Bohemian Rhapsody\r", rtPlus: queen, wantEntry: true, wantTitle: "Bohemian Rhapsody"},
+		// repeats of the same item are not reported
+		{rt: "Queen - Bohemian Rhapsody\r", rtPlus: queen},
+		{rt: "Queen - Bohemian Rhapsody\r", rtPlus: queen},
+		// RT changed before the RT+ tags were updated
+		{rt: "Abba - Waterloo\r", rtPlus: queen},
+		{rt: "Abba - Waterloo\r", rtPlus: RTPlus{}},
+		{rt: "Abba - Waterloo\r", rtPlus: RTPlus{}, wantEntry: true, wantTitle: "Waterloo"},
+		// the toggle bit flips for a new item with the same song
+		{rt: "Queen - Bohemian Rhapsody\r", rtPlus: queenAgain},
+		{rt: "Queen - Bohemian Rhapsody\r", rtPlus: queenAgain, wantEntry: true, wantTitle: "Bohemian Rhapsody"},
+		{rt: "News at noon\r", rtPlus: RTPlus{}},
+	}
+
+	tracker := NewNowPlayingTracker()
+	for i, r := range readings {
+		at := start.Add(time.Duration(i) * time.Second)
+		entry, ok := tracker.Update(at, r.rt, r.rtPlus)
+		if ok != r.wantEntry {
+			t.Errorf("reading %d: Update() ok = %v, want %v (%+v)", i, ok, r.wantEntry, entry)
+			continue
+		}
+		if ok && entry.Title != r.wantTitle {
+			t.Errorf("reading %d: Update() title = %q, want %q", i, entry.Title, r.wantTitle)
+		}
+		if ok && !entry.Time.Equal(at.Add(-time.Second)) {
+			t.Errorf("reading %d: Update() time = %v, want first reading %v", i, entry.Time, at.Add(-time.Second))
+		}
+	}
+}
+
+func TestNowPlayingTracker_PaddedRT(t *testing.T) {
+	// RT+ positions count the leading padding of the RadioText
+	rtPlus := RTPlus{
+		Status: RTPlusStatusRunning,
+		Item1:  RTPlusItem{Type: 4, Start: 2, Length: 4},
+		Item2:  RTPlusItem{Type: 1, Start: 10, Length: 16},
+	}
+	rt := "  Queen - Bohemian Rhapsody\r"
+	tracker := &NowPlayingTracker{Confirm: 1}
+	entry, ok := tracker.Update(time.Now(), rt, rtPlus)
+	if !ok {
+		t.Fatal("Update() ok = false, want entry")
+	}
+	if entry.Artist != "Queen" || entry.Title != "Bohemian Rhapsody" || entry.RT != "Queen - Bohemian Rhapsody" {
+		t.Errorf("Update() = %+v", entry)
+	}
+}
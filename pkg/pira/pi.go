@@ -0,0 +1,184 @@
+package pira
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// PI is the RDS programme identification code
+type PI uint16
+
+// CountryNibble returns the country identifier (bits 15-12)
+func (p PI) CountryNibble() byte {
+	return byte(p >> 12)
+}
+
+// Area returns the area coverage code (bits 11-8)
+func (p PI) Area() AreaCoverage {
+	return AreaCoverage(p>>8) & 0x0F
+}
+
+// Reference returns the programme reference number (bits 7-0)
+func (p PI) Reference() byte {
+	return byte(p)
+}
+
+// Country resolves the ISO 3166-1 alpha-2 country code from the PI
+// country nibble and the extended country code
+func (p PI) Country(ecc ECC) string {
+	return lookupCountry(ecc, p.CountryNibble())
+}
+
+func (p PI) String() string {
+	return fmt.Sprintf("%04X", uint16(p))
+}
+
+// MarshalJSON implements the json.Marshaler interface for PI
+func (p PI) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.String())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for PI,
+// it accepts both the hex string and a plain number
+func (p *PI) UnmarshalJSON(data []byte) error {
+	var code uint16
+	if err := json.Unmarshal(data, &code); err == nil {
+		*p = PI(code)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	v, err := strconv.ParseUint(s, 16, 16)
+	if err != nil {
+		return fmt.Errorf("invalid pi code: %s", s)
+	}
+	*p = PI(v)
+	return nil
+}
+
+// AreaCoverage is the PI area coverage code
+type AreaCoverage byte
+
+const (
+	AreaLocal         AreaCoverage = 0x0
+	AreaInternational AreaCoverage = 0x1
+	AreaNational      AreaCoverage = 0x2
+	AreaSupraRegional AreaCoverage = 0x3
+)
+
+// IsRegional reports whether the code is one of the twelve regional codes
+func (a AreaCoverage) IsRegional() bool {
+	return a >= 0x4
+}
+
+func (a AreaCoverage) String() string {
+	switch a {
+	case AreaLocal:
+		return "local"
+	case AreaInternational:
+		return "international"
+	case AreaNational:
+		return "national"
+	case AreaSupraRegional:
+		return "supra-regional"
+	}
+	return fmt.Sprintf("regional %d", a-0x3)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface for AreaCoverage
+func (a AreaCoverage) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface for AreaCoverage
+func (a *AreaCoverage) UnmarshalText(text []byte) error {
+	for code := AreaLocal; code <= 0xF; code++ {
+		if code.String() == string(text) {
+			*a = code
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown area coverage %q", text)
+}
+
+// rbdsThreeLetterCalls are the PI codes reserved for three letter call signs
+var rbdsThreeLetterCalls = map[PI]string{
+	0x99A5: "KBW", 0x99A6: "KCY", 0x99A7: "KDB", 0x99A8: "KDF", 0x99A9: "KEX",
+	0x99AA: "KFH", 0x99AB: "KFI", 0x99AC: "KGA", 0x99AD: "KGB", 0x99AE: "KGO",
+	0x99AF: "KGU", 0x99B0: "KGW", 0x99B1: "KGY", 0x99B2: "KHQ", 0x99B3: "KID",
+	0x99B4: "KIT", 0x99B5: "KJR", 0x99B6: "KLO", 0x99B7: "KLZ", 0x99B8: "KMA",
+	0x99B9: "KMJ", 0x99BA: "KNX", 0x99BB: "KOA", 0x99BC: "KOB", 0x99BD: "KOY",
+	0x99BE: "KPQ", 0x99BF: "KQV", 0x99C0: "KSD", 0x99C1: "KSL", 0x99C2: "KUJ",
+	0x99C3: "KUT", 0x99C4: "KVI", 0x99C5: "KWG", 0x99C6: "KXL", 0x99C7: "KXO",
+	0x99C8: "KYW", 0x99C9: "WBT", 0x99CA: "WBZ", 0x99CB: "WDZ", 0x99CC: "WEW",
+	0x99CD: "WGH", 0x99CE: "WGL", 0x99CF: "WGN", 0x99D0: "WGR", 0x99D1: "WGY",
+	0x99D2: "WHA", 0x99D3: "WHB", 0x99D4: "WHK", 0x99D5: "WHO", 0x99D6: "WHP",
+	0x99D7: "WIL", 0x99D8: "WIP", 0x99D9: "WIS", 0x99DA: "WJR", 0x99DB: "WJW",
+	0x99DC: "WJZ", 0x99DD: "WKY", 0x99DE: "WLS", 0x99DF: "WLW", 0x99E0: "WMC",
+	0x99E1: "WMT", 0x99E2: "WOC", 0x99E3: "WOI", 0x99E4: "WOL", 0x99E5: "WOR",
+	0x99E6: "WOW", 0x99E7: "WRC", 0x99E8: "WRR", 0x99E9: "WSB", 0x99EA: "WSM",
+	0x99EB: "WWJ", 0x99EC: "WWL",
+}
+
+// CallLetters converts an RBDS PI code into US call letters (NRSC-4 Annex D),
+// ok is false when the code does not map to a call sign
+func (p PI) CallLetters() (call string, ok bool) {
+	pi := p
+	// AxYZ is the compressed form of x0YZ
+	if pi>>12 == 0xA {
+		pi = (pi&0x0F00)<<4 | pi&0x00FF
+	}
+	if call, ok := rbdsThreeLetterCalls[pi]; ok {
+		return call, true
+	}
+
+	var (
+		prefix byte
+		n      int
+	)
+	switch {
+	case pi >= 4096 && pi <= 21671:
+		prefix, n = 'K', int(pi)-4096
+	case pi >= 21672 && pi <= 39247:
+		prefix, n = 'W', int(pi)-21672
+	default:
+		return "", false
+	}
+	return string([]byte{prefix, byte('A' + n/676), byte('A' + n%676/26), byte('A' + n%26)}), true
+}
+
+// PIInfo is a decoded PI code
+type PIInfo struct {
+	Code        PI           `json:"code"`
+	Country     string       `json:"country,omitempty"`
+	CountryName string       `json:"country_name,omitempty"`
+	Area        AreaCoverage `json:"area"`
+	Reference   byte         `json:"reference"`
+	CallLetters string       `json:"call_letters,omitempty"`
+}
+
+// Decode decodes the PI code using the extended country code, call
+// letters are only resolved for RBDS
+func (p PI) Decode(ecc ECC, region Region) PIInfo {
+	info := PIInfo{
+		Code:      p,
+		Country:   p.Country(ecc),
+		Area:      p.Area(),
+		Reference: p.Reference(),
+	}
+	if region == RegionRBDS {
+		if call, ok := p.CallLetters(); ok {
+			info.CallLetters = call
+			if info.Country == "" && ecc == 0 {
+				info.Country = "US"
+			}
+		}
+	}
+	if country, ok := CountryByCode(info.Country); ok {
+		info.CountryName = country.Name
+	}
+	return info
+}
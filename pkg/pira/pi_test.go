@@ -0,0 +1,140 @@
+package pira
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPI_Decode(t *testing.T) {
+	tests := []struct {
+		name   string
+		pi     PI
+		ecc    ECC
+		region Region
+		want   PIInfo
+	}{
+		{
+			name:   "croatian national",
+			pi:     0xC201,
+			ecc:    0xE3,
+			region: RegionRDS,
+			want:   PIInfo{Code: 0xC201, Country: "HR", CountryName: "Croatia", Area: AreaNational, Reference: 0x01},
+		},
+		{
+			name:   "german regional",
+			pi:     0xD3C2,
+			ecc:    0xE0,
+			region: RegionRDS,
+			want:   PIInfo{Code: 0xD3C2, Country: "DE", CountryName: "Germany", Area: 0x3, Reference: 0xC2},
+		},
+		{
+			name:   "unknown ecc",
+			pi:     0xC201,
+			ecc:    0x00,
+			region: RegionRDS,
+			want:   PIInfo{Code: 0xC201, Area: AreaNational, Reference: 0x01},
+		},
+		{
+			name:   "rbds call letters",
+			pi:     0x54A7,
+			ecc:    0x00,
+			region: RegionRBDS,
+			want:   PIInfo{Code: 0x54A7, Country: "US", CountryName: "United States", Area: 0x4, Reference: 0xA7, CallLetters: "KZZZ"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.pi.Decode(tt.ecc, tt.region); got != tt.want {
+				t.Errorf("PI.Decode() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPI_CallLetters(t *testing.T) {
+	tests := []struct {
+		name   string
+		pi     PI
+		want   string
+		wantOk bool
+	}{
+		{name: "first K", pi: 4096, want: "KAAA", wantOk: true},
+		{name: "first W", pi: 21672, want: "WAAA", wantOk: true},
+		{name: "last W", pi: 39247, want: "WZZZ", wantOk: true},
+		{name: "three letter", pi: 0x99C9, want: "WBT", wantOk: true},
+		{name: "compressed form", pi: 0xA1B2, want: "KAGW", wantOk: true},
+		{name: "out of range", pi: 0x0100, want: "", wantOk: false},
+		{name: "european code", pi: 0xC201, want: "", wantOk: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.pi.CallLetters()
+			if ok != tt.wantOk {
+				t.Errorf("PI.CallLetters() ok = %v, want %v", ok, tt.wantOk)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("PI.CallLetters() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAreaCoverage_String(t *testing.T) {
+	tests := []struct {
+		area AreaCoverage
+		want string
+	}{
+		{area: AreaLocal, want: "local"},
+		{area: AreaInternational, want: "international"},
+		{area: AreaNational, want: "national"},
+		{area: AreaSupraRegional, want: "supra-regional"},
+		{area: 0x4, want: "regional 1"},
+		{area: 0xF, want: "regional 12"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.area.String(); got != tt.want {
+				t.Errorf("AreaCoverage.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPI_JSON(t *testing.T) {
+	data, err := json.Marshal(PI(0xC201))
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if string(data) != `"C201"` {
+		t.Errorf("json.Marshal() = %v, want %v", string(data), `"C201"`)
+	}
+
+	tests := []struct {
+		name    string
+		data    string
+		want    PI
+		wantErr bool
+	}{
+		{name: "hex string", data: `"C201"`, want: 0xC201},
+		{name: "number", data: `49665`, want: 0xC201},
+		{name: "invalid", data: `"XYZ"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got PI
+			err := json.Unmarshal([]byte(tt.data), &got)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("json.Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("json.Unmarshal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package pira
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrInvalidPIN is returned for programme item numbers that are unset or out of range
+var ErrInvalidPIN = errors.New("invalid rds pin")
+
+// IsValid reports whether the PIN holds a schedule time, day zero marks an unset PIN
+func (r *RDSPIN) IsValid() bool {
+	return r.Day >= 1 && r.Day <= 31 && r.Hour <= 23 && r.Minute <= 59
+}
+
+// Time resolves the PIN, which only carries the day of the month, against
+// ref and returns the matching time closest to ref in the location of ref.
+// The PIN may point into the previous or the next month.
+func (r *RDSPIN) Time(ref time.Time) (time.Time, error) {
+	if !r.IsValid() {
+		return time.Time{}, ErrInvalidPIN
+	}
+	var (
+		best  time.Time
+		found bool
+	)
+	for _, month := range []int{-1, 0, 1} {
+		t := time.Date(ref.Year(), ref.Month()+time.Month(month), int(r.Day),
+			int(r.Hour), int(r.Minute), 0, 0, ref.Location())
+		// skip days the month does not have, time.Date normalizes them
+		if t.Day() != int(r.Day) {
+			continue
+		}
+		if !found || absDuration(t.Sub(ref)) < absDuration(best.Sub(ref)) {
+			best, found = t, true
+		}
+	}
+	if !found {
+		return time.Time{}, ErrInvalidPIN
+	}
+	return best, nil
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// pinReference is the time PINs are resolved against: the broadcast date
+// and local time offset when CT has been received, otherwise host
+func (r *RDSInfo) pinReference(host time.Time) time.Time {
+	if local, err := r.CT.Local(); err == nil {
+		return local
+	}
+	return host
+}
+
+// PINTime resolves the PIN against the broadcast date and local time
+// offset when CT has been received, otherwise against host
+func (r *RDSInfo) PINTime(host time.Time) (time.Time, error) {
+	return r.PIN.Time(r.pinReference(host))
+}
+
+// ProgrammeItem is a programme item number with its scheduled start
+type ProgrammeItem struct {
+	PIN RDSPIN `json:"pin"`
+	// Start is zero when the PIN is unset or cannot be resolved
+	Start time.Time `json:"start,omitzero"`
+}
+
+// newProgrammeItem resolves pin against ref
+func newProgrammeItem(pin RDSPIN, ref time.Time) ProgrammeItem {
+	item := ProgrammeItem{PIN: pin}
+	if start, err := pin.Time(ref); err == nil {
+		item.Start = start
+	}
+	return item
+}
+
+// ProgrammeBoundary is a change of the programme item number
+type ProgrammeBoundary struct {
+	Time     time.Time     `json:"time"`
+	Previous ProgrammeItem `json:"previous"`
+	Current  ProgrammeItem `json:"current"`
+}
+
+// PINTracker reports programme boundaries from successive PIN readings.
+// Unset PINs are skipped, so an item interrupted by an unset PIN does not
+// end.
+type PINTracker struct {
+	last    ProgrammeItem
+	started bool
+}
+
+// Update adds the PIN of rds read at time at and returns the boundary
+// when the PIN changed to a new valid value. PINs are resolved like
+// PINTime.
+func (t *PINTracker) Update(at time.Time, rds *RDSInfo) (ProgrammeBoundary, bool) {
+	return t.update(at, rds.PIN, rds.pinReference(at))
+}
+
+func (t *PINTracker) update(at time.Time, pin RDSPIN, ref time.Time) (ProgrammeBoundary, bool) {
+	if !t.started {
+		t.last, t.started = newProgrammeItem(pin, ref), true
+		return ProgrammeBoundary{}, false
+	}
+	if pin == t.last.PIN || !pin.IsValid() {
+		return ProgrammeBoundary{}, false
+	}
+	boundary := ProgrammeBoundary{Time: at, Previous: t.last, Current: newProgrammeItem(pin, ref)}
+	t.last = boundary.Current
+	return boundary, true
+}
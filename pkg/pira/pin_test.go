@@ -0,0 +1,109 @@
+package pira
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRDSPIN_Time(t *testing.T) {
+	ref := time.Date(2024, 3, 31, 23, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		pin     RDSPIN
+		ref     time.Time
+		want    time.Time
+		wantErr error
+	}{
+		{
+			name: "same day",
+			pin:  RDSPIN{Day: 31, Hour: 22, Minute: 30},
+			ref:  ref,
+			want: time.Date(2024, 3, 31, 22, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "next month",
+			pin:  RDSPIN{Day: 1, Hour: 6, Minute: 0},
+			ref:  ref,
+			want: time.Date(2024, 4, 1, 6, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "previous month",
+			pin:  RDSPIN{Day: 29, Hour: 20, Minute: 0},
+			ref:  time.Date(2024, 3, 1, 1, 0, 0, 0, time.UTC),
+			want: time.Date(2024, 2, 29, 20, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "unset",
+			pin:     RDSPIN{},
+			ref:     ref,
+			wantErr: ErrInvalidPIN,
+		},
+		{
+			name:    "hour out of range",
+			pin:     RDSPIN{Day: 3, Hour: 25},
+			ref:     ref,
+			wantErr: ErrInvalidPIN,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.pin.Time(tt.ref)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("RDSPIN.Time() error = %v, want %v", err, tt.wantErr)
+				return
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("RDSPIN.Time() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRDSInfo_PINTime(t *testing.T) {
+	// the broadcast clock says 2023-02-25 23:50 local (+02:00)
+	info := RDSInfo{
+		PIN: RDSPIN{Day: 26, Hour: 0, Minute: 5},
+		CT:  RDSCT{Hour: 21, Minute: 50, LocalTimeOffset: 0x04, MJD: mjdOf(60000)},
+	}
+	got, err := info.PINTime(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("RDSInfo.PINTime() error = %v", err)
+	}
+	if want := time.Date(2023, 2, 25, 22, 5, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("RDSInfo.PINTime() = %v, want %v", got, want)
+	}
+}
+
+func TestPINTracker_Update(t *testing.T) {
+	at := time.Date(2024, 3, 15, 8, 0, 0, 0, time.UTC)
+	tracker := PINTracker{}
+	pins := []struct {
+		pin  RDSPIN
+		want bool
+	}{
+		{pin: RDSPIN{Day: 15, Hour: 7, Minute: 0}, want: false},
+		{pin: RDSPIN{Day: 15, Hour: 7, Minute: 0}, want: false},
+		{pin: RDSPIN{}, want: false},
+		{pin: RDSPIN{Day: 15, Hour: 8, Minute: 0}, want: true},
+	}
+
+	for i, p := range pins {
+		boundary, ok := tracker.Update(at, &RDSInfo{PIN: p.pin})
+		if ok != p.want {
+			t.Errorf("reading %d: PINTracker.Update() ok = %v, want %v", i, ok, p.want)
+		}
+		if !ok {
+			continue
+		}
+		want := ProgrammeBoundary{
+			Time:     at,
+			Previous: ProgrammeItem{PIN: RDSPIN{Day: 15, Hour: 7}, Start: at.Add(-time.Hour)},
+			Current:  ProgrammeItem{PIN: RDSPIN{Day: 15, Hour: 8}, Start: at},
+		}
+		if boundary != want {
+			t.Errorf("reading %d: PINTracker.Update() = %+v, want %+v", i, boundary, want)
+		}
+	}
+}
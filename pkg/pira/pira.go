@@ -4,16 +4,27 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"sync"
 	"time"
 
 	"go.bug.st/serial"
 )
 
+// Pira is a connection to the analyzer. Reads and commands are serialized
+// so a client can be shared between goroutines, SendCommand and
+// RecvResponse are the unlocked building blocks.
 type Pira struct {
+	mu       sync.Mutex
 	port     string
 	baudRate int
 	conn     serial.Port
 	reader   *bufio.Reader
+
+	// settingsMu guards the client settings apart from mu, so they can be
+	// read while a read from the analyzer is in flight
+	settingsMu sync.RWMutex
+	region     Region
+	stations   StationDB
 }
 
 func Dial(port string, baudRate int, timeout time.Duration) (*Pira, error) {
@@ -33,6 +44,34 @@ func Dial(port string, baudRate int, timeout time.Duration) (*Pira, error) {
 	}, nil
 }
 
+// SetRegion selects the RDS or RBDS tables used to decode values read
+// from the analyzer
+func (p *Pira) SetRegion(region Region) {
+	p.settingsMu.Lock()
+	defer p.settingsMu.Unlock()
+	p.region = region
+}
+
+// Region returns the region used to decode values read from the analyzer
+func (p *Pira) Region() Region {
+	p.settingsMu.RLock()
+	defer p.settingsMu.RUnlock()
+	return p.region
+}
+
+// SetStationDB sets the station database used to name EON linked services
+func (p *Pira) SetStationDB(db StationDB) {
+	p.settingsMu.Lock()
+	defer p.settingsMu.Unlock()
+	p.stations = db
+}
+
+func (p *Pira) stationDB() StationDB {
+	p.settingsMu.RLock()
+	defer p.settingsMu.RUnlock()
+	return p.stations
+}
+
 func (p *Pira) Close() error {
 	return p.conn.Close()
 }
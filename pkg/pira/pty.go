@@ -0,0 +1,193 @@
+package pira
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Region selects the programme type table used to name PTY codes
+type Region byte
+
+const (
+	// RegionRDS is the European RDS (IEC 62106) table
+	RegionRDS Region = 0
+	// RegionRBDS is the North American RBDS (NRSC-4) table
+	RegionRBDS Region = 1
+)
+
+func (r Region) String() string {
+	if r == RegionRBDS {
+		return "RBDS"
+	}
+	return "RDS"
+}
+
+// ParseRegion parses "rds" or "rbds" (case insensitive)
+func ParseRegion(s string) (Region, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "rds", "eu", "":
+		return RegionRDS, nil
+	case "rbds", "us", "na":
+		return RegionRBDS, nil
+	}
+	return RegionRDS, fmt.Errorf("unknown region: %s", s)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface for Region
+func (r Region) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface for Region
+func (r *Region) UnmarshalText(text []byte) error {
+	region, err := ParseRegion(string(text))
+	if err != nil {
+		return err
+	}
+	*r = region
+	return nil
+}
+
+type ptyName struct {
+	short string
+	long  string
+}
+
+var rdsPTYNames = [32]ptyName{
+	{"None", "None"},
+	{"News", "News"},
+	{"Affairs", "Current Affairs"},
+	{"Info", "Information"},
+	{"Sport", "Sport"},
+	{"Educate", "Education"},
+	{"Drama", "Drama"},
+	{"Culture", "Culture"},
+	{"Science", "Science"},
+	{"Varied", "Varied"},
+	{"Pop M", "Pop Music"},
+	{"Rock M", "Rock Music"},
+	{"Easy M", "Easy Listening"},
+	{"Light M", "Light Classical"},
+	{"Classics", "Serious Classical"},
+	{"Other M", "Other Music"},
+	{"Weather", "Weather"},
+	{"Finance", "Finance"},
+	{"Children", "Children's Progs"},
+	{"Social", "Social Affairs"},
+	{"Religion", "Religion"},
+	{"Phone In", "Phone In"},
+	{"Travel", "Travel"},
+	{"Leisure", "Leisure"},
+	{"Jazz", "Jazz Music"},
+	{"Country", "Country Music"},
+	{"Nation M", "National Music"},
+	{"Oldies", "Oldies Music"},
+	{"Folk M", "Folk Music"},
+	{"Document", "Documentary"},
+	{"TEST", "Alarm Test"},
+	{"Alarm !", "Alarm - Alarm !"},
+}
+
+var rbdsPTYNames = [32]ptyName{
+	{"None", "None"},
+	{"News", "News"},
+	{"Inform", "Information"},
+	{"Sports", "Sports"},
+	{"Talk", "Talk"},
+	{"Rock", "Rock"},
+	{"Cls Rock", "Classic Rock"},
+	{"Adlt Hit", "Adult Hits"},
+	{"Soft Rck", "Soft Rock"},
+	{"Top 40", "Top 40"},
+	{"Country", "Country"},
+	{"Oldies", "Oldies"},
+	{"Soft", "Soft"},
+	{"Nostalga", "Nostalgia"},
+	{"Jazz", "Jazz"},
+	{"Classicl", "Classical"},
+	{"R & B", "Rhythm and Blues"},
+	{"Soft R&B", "Soft R & B"},
+	{"Language", "Foreign Language"},
+	{"Rel Musc", "Religious Music"},
+	{"Rel Talk", "Religious Talk"},
+	{"Persnlty", "Personality"},
+	{"Public", "Public"},
+	{"College", "College"},
+	{"Habl Esp", "Spanish Talk"},
+	{"Musc Esp", "Spanish Music"},
+	{"Hip Hop", "Hip Hop"},
+	{"", ""},
+	{"", ""},
+	{"Weather", "Weather"},
+	{"Test", "Emergency Test"},
+	{"ALERT!", "Emergency"},
+}
+
+// PTY is the RDS programme type code together with the region
+// that determines how it is named
+type PTY struct {
+	Code   byte
+	Region Region
+}
+
+func (p PTY) name() ptyName {
+	if p.Code > 31 {
+		return ptyName{}
+	}
+	if p.Region == RegionRBDS {
+		return rbdsPTYNames[p.Code]
+	}
+	return rdsPTYNames[p.Code]
+}
+
+// ShortName returns the 8 character display name
+func (p PTY) ShortName() string {
+	return p.name().short
+}
+
+// LongName returns the 16 character display name
+func (p PTY) LongName() string {
+	return p.name().long
+}
+
+func (p PTY) String() string {
+	if name := p.LongName(); name != "" {
+		return name
+	}
+	return fmt.Sprintf("PTY %d", p.Code)
+}
+
+type ptyJSON struct {
+	Code     byte   `json:"code"`
+	Name     string `json:"name"`
+	LongName string `json:"long_name"`
+	Region   Region `json:"region"`
+}
+
+// MarshalJSON implements the json.Marshaler interface for PTY
+func (p PTY) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ptyJSON{
+		Code:     p.Code,
+		Name:     p.ShortName(),
+		LongName: p.LongName(),
+		Region:   p.Region,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for PTY,
+// it accepts both the object form and a bare code
+func (p *PTY) UnmarshalJSON(data []byte) error {
+	var code byte
+	if err := json.Unmarshal(data, &code); err == nil {
+		p.Code = code
+		return nil
+	}
+	var v ptyJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	p.Code = v.Code
+	p.Region = v.Region
+	return nil
+}
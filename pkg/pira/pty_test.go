@@ -0,0 +1,134 @@
+package pira
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPTY_Names(t *testing.T) {
+	tests := []struct {
+		name      string
+		pty       PTY
+		wantShort string
+		wantLong  string
+	}{
+		{
+			name:      "rds rock",
+			pty:       PTY{Code: 11, Region: RegionRDS},
+			wantShort: "Rock M",
+			wantLong:  "Rock Music",
+		},
+		{
+			name:      "rbds classic rock",
+			pty:       PTY{Code: 6, Region: RegionRBDS},
+			wantShort: "Cls Rock",
+			wantLong:  "Classic Rock",
+		},
+		{
+			name:      "rds none",
+			pty:       PTY{Code: 0, Region: RegionRDS},
+			wantShort: "None",
+			wantLong:  "None",
+		},
+		{
+			name:      "out of range",
+			pty:       PTY{Code: 40, Region: RegionRDS},
+			wantShort: "",
+			wantLong:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.pty.ShortName(); got != tt.wantShort {
+				t.Errorf("PTY.ShortName() = %v, want %v", got, tt.wantShort)
+			}
+			if got := tt.pty.LongName(); got != tt.wantLong {
+				t.Errorf("PTY.LongName() = %v, want %v", got, tt.wantLong)
+			}
+		})
+	}
+}
+
+func TestPTY_String(t *testing.T) {
+	tests := []struct {
+		name string
+		pty  PTY
+		want string
+	}{
+		{
+			name: "named code",
+			pty:  PTY{Code: 1, Region: RegionRDS},
+			want: "News",
+		},
+		{
+			name: "unassigned rbds code",
+			pty:  PTY{Code: 27, Region: RegionRBDS},
+			want: "PTY 27",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.pty.String(); got != tt.want {
+				t.Errorf("PTY.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPTY_JSON_RoundTrip(t *testing.T) {
+	pty := PTY{Code: 6, Region: RegionRBDS}
+	data, err := json.Marshal(pty)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	want := `{"code":6,"name":"Cls Rock","long_name":"Classic Rock","region":"RBDS"}`
+	if string(data) != want {
+		t.Errorf("json.Marshal() = %v, want %v", string(data), want)
+	}
+
+	var got PTY
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if got != pty {
+		t.Errorf("json.Unmarshal() = %v, want %v", got, pty)
+	}
+}
+
+func TestPTY_UnmarshalJSON_Code(t *testing.T) {
+	var got PTY
+	if err := json.Unmarshal([]byte("11"), &got); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if got.Code != 11 {
+		t.Errorf("json.Unmarshal() code = %v, want 11", got.Code)
+	}
+}
+
+func TestParseRegion(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Region
+		wantErr bool
+	}{
+		{input: "rds", want: RegionRDS},
+		{input: "RBDS", want: RegionRBDS},
+		{input: "", want: RegionRDS},
+		{input: "mars", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseRegion(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseRegion() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseRegion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package pira
+
+import "fmt"
+
+// RT+ status bits, laid out as in the RT+ group block B
+const (
+	RTPlusStatusToggle  byte = 0x10
+	RTPlusStatusRunning byte = 0x08
+)
+
+// RTPlusContentType is the RT+ content type code
+type RTPlusContentType byte
+
+const (
+	RTPlusDummy       RTPlusContentType = 0
+	RTPlusItemTitle   RTPlusContentType = 1
+	RTPlusItemAlbum   RTPlusContentType = 2
+	RTPlusItemArtist  RTPlusContentType = 4
+	RTPlusItemBand    RTPlusContentType = 9
+	RTPlusStationLong RTPlusContentType = 32
+	RTPlusProgNow     RTPlusContentType = 33
+	RTPlusProgNext    RTPlusContentType = 34
+)
+
+var rtPlusContentTypes = [64]string{
+	"DUMMY_CLASS",
+	"ITEM.TITLE",
+	"ITEM.ALBUM",
+	"ITEM.TRACKNUMBER",
+	"ITEM.ARTIST",
+	"ITEM.COMPOSITION",
+	"ITEM.MOVEMENT",
+	"ITEM.CONDUCTOR",
+	"ITEM.COMPOSER",
+	"ITEM.BAND",
+	"ITEM.COMMENT",
+	"ITEM.GENRE",
+	"INFO.NEWS",
+	"INFO.NEWS.LOCAL",
+	"INFO.STOCKMARKET",
+	"INFO.SPORT",
+	"INFO.LOTTERY",
+	"INFO.HOROSCOPE",
+	"INFO.DAILY_DIVERSION",
+	"INFO.HEALTH",
+	"INFO.EVENT",
+	"INFO.SCENE",
+	"INFO.CINEMA",
+	"INFO.STUPIDITY_MACHINE",
+	"INFO.DATE_TIME",
+	"INFO.WEATHER",
+	"INFO.TRAFFIC",
+	"INFO.ALARM",
+	"INFO.ADVERTISEMENT",
+	"INFO.URL",
+	"INFO.OTHER",
+	"STATIONNAME.SHORT",
+	"STATIONNAME.LONG",
+	"PROGRAMME.NOW",
+	"PROGRAMME.NEXT",
+	"PROGRAMME.PART",
+	"PROGRAMME.HOST",
+	"PROGRAMME.EDITORIAL_STAFF",
+	"PROGRAMME.FREQUENCY",
+	"PROGRAMME.HOMEPAGE",
+	"PROGRAMME.SUBCHANNEL",
+	"PHONE.HOTLINE",
+	"PHONE.STUDIO",
+	"PHONE.OTHER",
+	"SMS.STUDIO",
+	"SMS.OTHER",
+	"EMAIL.HOTLINE",
+	"EMAIL.STUDIO",
+	"EMAIL.OTHER",
+	"MMS.OTHER",
+	"CHAT",
+	"CHAT.CENTRE",
+	"VOTE.QUESTION",
+	"VOTE.CENTRE",
+	"RFU",
+	"RFU",
+	"PRIVATE_CLASS_1",
+	"PRIVATE_CLASS_2",
+	"PRIVATE_CLASS_3",
+	"PLACE",
+	"APPOINTMENT",
+	"IDENTIFIER",
+	"PURCHASE",
+	"GET_DATA",
+}
+
+func (t RTPlusContentType) String() string {
+	if t > 63 {
+		return fmt.Sprintf("RTPLUS_%d", t)
+	}
+	return rtPlusContentTypes[t]
+}
+
+// Running reports whether the item running bit is set, tags are only
+// meaningful while an item is running
+func (r *RTPlus) Running() bool {
+	return r.Status&RTPlusStatusRunning != 0
+}
+
+// Toggle returns the item toggle bit, which flips when a new item starts
+func (r *RTPlus) Toggle() bool {
+	return r.Status&RTPlusStatusToggle != 0
+}
+
+// RTPlusTag is a RadioText substring tagged with an RT+ content type
+type RTPlusTag struct {
+	Type RTPlusContentType `json:"type"`
+	Name string            `json:"name"`
+	Text string            `json:"text"`
+}
+
+// Tags applies both RT+ items to rt and returns the tagged substrings,
+// nil when no item is running
+func (r *RTPlus) Tags(rt string) []RTPlusTag {
+	if !r.Running() {
+		return nil
+	}
+	var tags []RTPlusTag
+	for _, item := range []RTPlusItem{r.Item1, r.Item2} {
+		text, ok := item.extract(rt)
+		if !ok {
+			continue
+		}
+		contentType := RTPlusContentType(item.Type)
+		tags = append(tags, RTPlusTag{Type: contentType, Name: contentType.String(), Text: text})
+	}
+	return tags
+}
+
+// extract returns the substring of rt the item points at, the length
+// field holds the number of characters minus one
+func (i RTPlusItem) extract(rt string) (string, bool) {
+	if RTPlusContentType(i.Type) == RTPlusDummy {
+		return "", false
+	}
+	start, end := int(i.Start), int(i.Start)+int(i.Length)+1
+	if end > len(rt) {
+		return "", false
+	}
+	text := cleanText(rt[start:end])
+	return text, text != ""
+}
+
+// NowPlaying is the structured item metadata carried by RT+
+type NowPlaying struct {
+	Artist  string `json:"artist,omitempty"`
+	Title   string `json:"title,omitempty"`
+	Album   string `json:"album,omitempty"`
+	Running bool   `json:"running"`
+	Toggle  bool   `json:"toggle"`
+}
+
+// IsEmpty reports whether neither artist nor title is known
+func (n NowPlaying) IsEmpty() bool {
+	return n.Artist == "" && n.Title == ""
+}
+
+// RTPlusTags returns the RT+ tagged substrings of the RadioText
+func (r *RDSInfo) RTPlusTags() []RTPlusTag {
+	return r.RTPlus.Tags(r.RT)
+}
+
+// NowPlaying extracts the current item from RT+ tags
+func (r *RDSInfo) NowPlaying() NowPlaying {
+	return r.RTPlus.NowPlaying(r.RT)
+}
+
+// NowPlaying extracts the current item from rt
+func (r *RTPlus) NowPlaying(rt string) NowPlaying {
+	np := NowPlaying{Running: r.Running(), Toggle: r.Toggle()}
+	for _, tag := range r.Tags(rt) {
+		switch tag.Type {
+		case RTPlusItemArtist, RTPlusItemBand:
+			if np.Artist == "" {
+				np.Artist = tag.Text
+			}
+		case RTPlusItemTitle:
+			np.Title = tag.Text
+		case RTPlusItemAlbum:
+			np.Album = tag.Text
+		}
+	}
+	return np
+}
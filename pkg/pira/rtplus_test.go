@@ -0,0 +1,97 @@
+package pira
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRTPlus_Tags(t *testing.T) {
+	rt := "Now playing: Queen - Bohemian Rhapsody\r"
+	tests := []struct {
+		name   string
+		rtPlus RTPlus
+		want   []RTPlusTag
+	}{
+		{
+			name: "artist and title",
+			rtPlus: RTPlus{
+				Status: RTPlusStatusRunning,
+				Item1:  RTPlusItem{Type: 4, Start: 13, Length: 4},
+				Item2:  RTPlusItem{Type: 1, Start: 21, Length: 16},
+			},
+			want: []RTPlusTag{
+				{Type: RTPlusItemArtist, Name: "ITEM.ARTIST", Text: "Queen"},
+				{Type: RTPlusItemTitle, Name: "ITEM.TITLE", Text: "Bohemian Rhapsody"},
+			},
+		},
+		{
+			name: "item not running",
+			rtPlus: RTPlus{
+				Item1: RTPlusItem{Type: 4, Start: 13, Length: 4},
+			},
+			want: nil,
+		},
+		{
+			name: "dummy and out of range items",
+			rtPlus: RTPlus{
+				Status: RTPlusStatusRunning,
+				Item1:  RTPlusItem{Type: 0, Start: 0, Length: 2},
+				Item2:  RTPlusItem{Type: 1, Start: 60, Length: 10},
+			},
+			want: nil,
+		},
+		{
+			name: "tag runs into terminator",
+			rtPlus: RTPlus{
+				Status: RTPlusStatusRunning,
+				Item1:  RTPlusItem{Type: 1, Start: 21, Length: 17},
+			},
+			want: []RTPlusTag{
+				{Type: RTPlusItemTitle, Name: "ITEM.TITLE", Text: "Bohemian Rhapsody"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rtPlus.Tags(rt); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RTPlus.Tags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRDSInfo_NowPlaying(t *testing.T) {
+	info := RDSInfo{
+		RT: "Queen - Bohemian Rhapsody",
+		RTPlus: RTPlus{
+			Status: RTPlusStatusRunning | RTPlusStatusToggle,
+			Item1:  RTPlusItem{Type: 4, Start: 0, Length: 4},
+			Item2:  RTPlusItem{Type: 1, Start: 8, Length: 16},
+		},
+	}
+	want := NowPlaying{Artist: "Queen", Title: "Bohemian Rhapsody", Running: true, Toggle: true}
+	if got := info.NowPlaying(); got != want {
+		t.Errorf("RDSInfo.NowPlaying() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRTPlusContentType_String(t *testing.T) {
+	tests := []struct {
+		contentType RTPlusContentType
+		want        string
+	}{
+		{contentType: RTPlusItemTitle, want: "ITEM.TITLE"},
+		{contentType: RTPlusItemArtist, want: "ITEM.ARTIST"},
+		{contentType: 63, want: "GET_DATA"},
+		{contentType: 64, want: "RTPLUS_64"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.contentType.String(); got != tt.want {
+				t.Errorf("RTPlusContentType.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
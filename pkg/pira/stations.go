@@ -0,0 +1,68 @@
+package pira
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Station is an entry of the local station database
+type Station struct {
+	PI        PI      `json:"pi"`
+	Name      string  `json:"name"`
+	PS        string  `json:"ps,omitempty"`
+	Frequency float64 `json:"frequency,omitempty"`
+}
+
+// StationDB maps PI codes to known stations
+type StationDB map[PI]Station
+
+// ReadStationDB reads a JSON array of stations
+func ReadStationDB(r io.Reader) (StationDB, error) {
+	var stations []Station
+	if err := json.NewDecoder(r).Decode(&stations); err != nil {
+		return nil, fmt.Errorf("failed to decode station database: %w", err)
+	}
+	db := make(StationDB, len(stations))
+	for _, s := range stations {
+		db[s.PI] = s
+	}
+	return db, nil
+}
+
+// LoadStationDB reads the station database from a JSON file
+func LoadStationDB(path string) (StationDB, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadStationDB(f)
+}
+
+// LinkedService is an other network referenced through EON
+type LinkedService struct {
+	PI   PI     `json:"pi"`
+	Info PIInfo `json:"info"`
+	Name string `json:"name,omitempty"`
+	PS   string `json:"ps,omitempty"`
+}
+
+// LinkedServices decodes the EON PI codes and looks them up in db, which
+// may be nil. The other networks are assumed to share the tuned ECC.
+func (r *RDSInfo) LinkedServices(db StationDB, region Region) []LinkedService {
+	var services []LinkedService
+	for _, pi := range r.EONPI {
+		if pi == 0 {
+			continue
+		}
+		service := LinkedService{PI: pi, Info: pi.Decode(r.ECC, region)}
+		if station, ok := db[pi]; ok {
+			service.Name = station.Name
+			service.PS = station.PS
+		}
+		services = append(services, service)
+	}
+	return services
+}
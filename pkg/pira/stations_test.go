@@ -0,0 +1,49 @@
+package pira
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadStationDB(t *testing.T) {
+	input := `[
+		{"pi": "C202", "name": "Radio Two", "ps": "RADIO 2 "},
+		{"pi": 49667, "name": "Radio Three"}
+	]`
+	db, err := ReadStationDB(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadStationDB() error = %v", err)
+	}
+	if len(db) != 2 || db[0xC202].Name != "Radio Two" || db[0xC203].Name != "Radio Three" {
+		t.Errorf("ReadStationDB() = %v", db)
+	}
+
+	if _, err := ReadStationDB(strings.NewReader(`{"pi": 1}`)); err == nil {
+		t.Errorf("ReadStationDB() error = nil, want error")
+	}
+}
+
+func TestRDSInfo_LinkedServices(t *testing.T) {
+	db := StationDB{0xC202: {PI: 0xC202, Name: "Radio Two", PS: "RADIO 2 "}}
+	info := RDSInfo{ECC: 0xE3, EONPI: [4]PI{0xC202, 0xC5A3}}
+
+	want := []LinkedService{
+		{
+			PI:   0xC202,
+			Info: PIInfo{Code: 0xC202, Country: "HR", CountryName: "Croatia", Area: AreaNational, Reference: 0x02},
+			Name: "Radio Two",
+			PS:   "RADIO 2 ",
+		},
+		{
+			PI:   0xC5A3,
+			Info: PIInfo{Code: 0xC5A3, Country: "HR", CountryName: "Croatia", Area: 0x5, Reference: 0xA3},
+		},
+	}
+	if got := info.LinkedServices(db, RegionRDS); !reflect.DeepEqual(got, want) {
+		t.Errorf("RDSInfo.LinkedServices() = %+v, want %+v", got, want)
+	}
+	if got := (&RDSInfo{}).LinkedServices(nil, RegionRDS); got != nil {
+		t.Errorf("RDSInfo.LinkedServices() = %+v, want nil", got)
+	}
+}
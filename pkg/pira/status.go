@@ -0,0 +1,101 @@
+package pira
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// DI is the 4 bit RDS decoder identification, bit 0 is d0
+type DI byte
+
+const (
+	DIStereo         DI = 0b0001
+	DIArtificialHead DI = 0b0010
+	DICompressed     DI = 0b0100
+	DIDynamicPTY     DI = 0b1000
+)
+
+// Stereo reports stereo (true) or mono (false) transmission
+func (d DI) Stereo() bool {
+	return d&DIStereo != 0
+}
+
+// ArtificialHead reports an artificial head recording
+func (d DI) ArtificialHead() bool {
+	return d&DIArtificialHead != 0
+}
+
+// Compressed reports compressed audio
+func (d DI) Compressed() bool {
+	return d&DICompressed != 0
+}
+
+// DynamicPTY reports that PTY is switched dynamically
+func (d DI) DynamicPTY() bool {
+	return d&DIDynamicPTY != 0
+}
+
+func (d DI) String() string {
+	flags := make([]string, 0, 4)
+	if d.Stereo() {
+		flags = append(flags, "stereo")
+	} else {
+		flags = append(flags, "mono")
+	}
+	if d.ArtificialHead() {
+		flags = append(flags, "artificial head")
+	}
+	if d.Compressed() {
+		flags = append(flags, "compressed")
+	}
+	if d.DynamicPTY() {
+		flags = append(flags, "dynamic pty")
+	}
+	return strings.Join(flags, ", ")
+}
+
+// MusicSpeech renders the MS flag
+func (s RDSStatus) MusicSpeech() string {
+	if s.MS {
+		return "music"
+	}
+	return "speech"
+}
+
+func (s RDSStatus) String() string {
+	flags := make([]string, 0, 8)
+	for _, f := range []struct {
+		name string
+		set  bool
+	}{{"CT", s.CT}, {"RT", s.RT}, {"AF", s.AF}, {"TP", s.TP}, {"TA", s.TA}} {
+		if f.set {
+			flags = append(flags, f.name)
+		}
+	}
+	if s.RT {
+		flags = append(flags, s.RTType.String())
+	}
+	flags = append(flags, s.MusicSpeech(), s.DI.String())
+	return strings.Join(flags, " ")
+}
+
+// MarshalJSON implements the json.Marshaler interface for RDSStatus, the
+// raw fields are kept and the decoded DI and MS flags are added
+func (s RDSStatus) MarshalJSON() ([]byte, error) {
+	type rdsStatus RDSStatus
+	return json.Marshal(struct {
+		rdsStatus
+		MusicSpeech    string `json:"music_speech"`
+		Stereo         bool   `json:"stereo"`
+		ArtificialHead bool   `json:"artificial_head"`
+		Compressed     bool   `json:"compressed"`
+		DynamicPTY     bool   `json:"dynamic_pty"`
+	}{
+		rdsStatus:      rdsStatus(s),
+		MusicSpeech:    s.MusicSpeech(),
+		Stereo:         s.DI.Stereo(),
+		ArtificialHead: s.DI.ArtificialHead(),
+		Compressed:     s.DI.Compressed(),
+		DynamicPTY:     s.DI.DynamicPTY(),
+	})
+}
@@ -0,0 +1,75 @@
+package pira
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseRDSStatus_DI(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     uint16
+		wantStereo bool
+		wantDI     string
+		wantMS     string
+	}{
+		{
+			name:       "stereo music",
+			status:     0b00000011001,
+			wantStereo: true,
+			wantDI:     "stereo, dynamic pty",
+			wantMS:     "music",
+		},
+		{
+			name:       "mono speech",
+			status:     0b00000000110,
+			wantStereo: false,
+			wantDI:     "mono, artificial head, compressed",
+			wantMS:     "speech",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := parseRDSStatus(tt.status)
+			if got := status.DI.Stereo(); got != tt.wantStereo {
+				t.Errorf("DI.Stereo() = %v, want %v", got, tt.wantStereo)
+			}
+			if got := status.DI.String(); got != tt.wantDI {
+				t.Errorf("DI.String() = %v, want %v", got, tt.wantDI)
+			}
+			if got := status.MusicSpeech(); got != tt.wantMS {
+				t.Errorf("RDSStatus.MusicSpeech() = %v, want %v", got, tt.wantMS)
+			}
+		})
+	}
+}
+
+func TestRDSStatus_String(t *testing.T) {
+	status := RDSStatus{RT: true, RTType: RTTypeB, TP: true, TA: true, MS: true, DI: DIStereo}
+	want := "RT TP TA RTB music stereo"
+	if got := status.String(); got != want {
+		t.Errorf("RDSStatus.String() = %v, want %v", got, want)
+	}
+}
+
+func TestRDSStatus_MarshalJSON(t *testing.T) {
+	status := RDSStatus{TP: true, MS: true, DI: DIStereo | DICompressed}
+	data, err := json.Marshal(status)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	want := `{"ct":false,"rt":false,"rt_type":0,"af":false,"tp":true,"ta":false,"ms":true,"di":5,` +
+		`"music_speech":"music","stereo":true,"artificial_head":false,"compressed":true,"dynamic_pty":false}`
+	if string(data) != want {
+		t.Errorf("json.Marshal() = %v, want %v", string(data), want)
+	}
+
+	var got RDSStatus
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if got != status {
+		t.Errorf("json.Unmarshal() = %+v, want %+v", got, status)
+	}
+}
@@ -9,6 +9,8 @@ import (
 )
 
 func (p *Pira) GetBasicData() (*BasicData, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 	n, err := p.SendCommand(CmdGetBasicData)
 	if err != nil {
 		return nil, err
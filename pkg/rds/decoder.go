@@ -0,0 +1,339 @@
+// Package rds decodes raw RDS groups into the same RDSInfo state that the
+// analyzer exposes in its memory.
+package rds
+
+import (
+	"bytes"
+	"math"
+
+	"go-pira/pkg/pira"
+)
+
+// AIDRTPlus is the open data application identifier of RadioText Plus
+const AIDRTPlus uint16 = 0x4BD7
+
+const (
+	afFiller   = 205
+	afLFMF     = 250
+	afMaxCount = 25
+)
+
+// Decoder assembles RDS groups into an RDSInfo. Blocks marked as errors
+// are skipped, text segments are kept until the A/B flag changes and the
+// whole state is reset when the PI code changes.
+type Decoder struct {
+	region pira.Region
+	info   pira.RDSInfo
+
+	ps     [8]byte
+	rt     [64]byte
+	rtAB   int
+	rtEnd  int
+	ptyn   [8]byte
+	ptynAB int
+	longPS [32]byte
+
+	afs         []float64
+	afLFMF      bool
+	eon         []pira.PI
+	rtPlusGroup int
+}
+
+// NewDecoder returns a decoder naming programme types for region
+func NewDecoder(region pira.Region) *Decoder {
+	d := &Decoder{region: region}
+	d.Reset()
+	return d
+}
+
+// Reset clears all decoded state
+func (d *Decoder) Reset() {
+	*d = Decoder{
+		region:      d.region,
+		ps:          [8]byte(bytes.Repeat([]byte{' '}, 8)),
+		rt:          [64]byte(bytes.Repeat([]byte{' '}, 64)),
+		rtAB:        -1,
+		rtEnd:       -1,
+		ptyn:        [8]byte(bytes.Repeat([]byte{' '}, 8)),
+		ptynAB:      -1,
+		rtPlusGroup: -1,
+	}
+}
+
+// Decode adds a group to the decoded state
+func (d *Decoder) Decode(g Group) error {
+	if !g.Valid(BlockB) {
+		return ErrNoBlockB
+	}
+	switch {
+	case g.Valid(BlockA):
+		d.setPI(pira.PI(g.Blocks[BlockA]))
+	case g.IsB() && g.Valid(BlockC):
+		d.setPI(pira.PI(g.Blocks[BlockC]))
+	}
+
+	b := g.Blocks[BlockB]
+	d.info.Groups[g.Index()]++
+	d.info.Status.TP = b&0x0400 != 0
+	d.info.PTY = pira.PTY{Code: byte(b>>5) & 0x1F, Region: d.region}
+
+	if g.Index() == d.rtPlusGroup {
+		d.decodeRTPlus(g)
+		return nil
+	}
+
+	switch g.Name() {
+	case "0A", "0B":
+		d.decodeBasic(g)
+	case "1A", "1B":
+		d.decodeSlowLabelling(g)
+	case "2A", "2B":
+		d.decodeRT(g)
+	case "3A":
+		d.decodeODA(g)
+	case "4A":
+		d.decodeCT(g)
+	case "10A":
+		d.decodePTYN(g)
+	case "14A", "14B":
+		d.decodeEON(g)
+	case "15A":
+		d.decodeLongPS(g)
+	case "15B":
+		d.decodeFlags(g)
+	}
+	return nil
+}
+
+func (d *Decoder) setPI(pi pira.PI) {
+	if d.info.PI != 0 && d.info.PI != pi {
+		d.Reset()
+	}
+	d.info.PI = pi
+}
+
+// decodeFlags decodes TA, MS and one DI bit shared by groups 0 and 15B
+func (d *Decoder) decodeFlags(g Group) {
+	b := g.Blocks[BlockB]
+	d.info.Status.TA = b&0x0010 != 0
+	d.info.Status.MS = b&0x0008 != 0
+	// segment 0 carries d3 and segment 3 carries d0
+	bit := pira.DI(1) << (3 - b&0x03)
+	if b&0x0004 != 0 {
+		d.info.Status.DI |= bit
+	} else {
+		d.info.Status.DI &^= bit
+	}
+}
+
+func (d *Decoder) decodeBasic(g Group) {
+	d.decodeFlags(g)
+	if !g.IsB() && g.Valid(BlockC) {
+		c := g.Blocks[BlockC]
+		d.addAF(byte(c >> 8))
+		d.addAF(byte(c))
+	} else {
+		d.afLFMF = false
+	}
+	if g.Valid(BlockD) {
+		segment := int(g.Blocks[BlockB]&0x03) * 2
+		putChars(d.ps[segment:], g.Blocks[BlockD])
+	}
+}
+
+// addAF adds an FM frequency code, the code following afLFMF is an LF/MF
+// frequency and is skipped
+func (d *Decoder) addAF(code byte) {
+	if d.afLFMF {
+		d.afLFMF = false
+		return
+	}
+	if code == afLFMF {
+		d.afLFMF = true
+		return
+	}
+	if code == 0 || code > 204 || len(d.afs) >= afMaxCount {
+		return
+	}
+	freq := math.Round((87.5+float64(code)*0.1)*10) / 10
+	for _, af := range d.afs {
+		if af == freq {
+			return
+		}
+	}
+	d.afs = append(d.afs, freq)
+}
+
+func (d *Decoder) decodeSlowLabelling(g Group) {
+	if !g.IsB() && g.Valid(BlockC) {
+		c := g.Blocks[BlockC]
+		switch (c >> 12) & 0x07 {
+		case 0:
+			d.info.ECC = pira.ECC(c)
+		case 3:
+			d.info.LIC = pira.LIC(c)
+		}
+	}
+	if g.Valid(BlockD) {
+		pin := g.Blocks[BlockD]
+		d.info.PIN = pira.RDSPIN{
+			Day:    byte(pin >> 11),
+			Hour:   byte(pin>>6) & 0x1F,
+			Minute: byte(pin) & 0x3F,
+		}
+	}
+}
+
+func (d *Decoder) decodeRT(g Group) {
+	b := g.Blocks[BlockB]
+	ab := int(b>>4) & 0x01
+	if ab != d.rtAB {
+		d.rt = [64]byte(bytes.Repeat([]byte{' '}, 64))
+		d.rtEnd = -1
+		d.rtAB = ab
+	}
+	d.info.Status.RT = true
+	d.info.Status.RTType = pira.RTType(ab)
+
+	segment := int(b & 0x0F)
+	if g.IsB() {
+		if g.Valid(BlockD) {
+			d.putRT(segment*2, g.Blocks[BlockD])
+		}
+		return
+	}
+	if g.Valid(BlockC) {
+		d.putRT(segment*4, g.Blocks[BlockC])
+	}
+	if g.Valid(BlockD) {
+		d.putRT(segment*4+2, g.Blocks[BlockD])
+	}
+}
+
+func (d *Decoder) putRT(pos int, block uint16) {
+	putChars(d.rt[pos:], block)
+	for i := pos; i < pos+2; i++ {
+		if d.rt[i] == '\r' && (d.rtEnd < 0 || i < d.rtEnd) {
+			d.rtEnd = i
+		}
+	}
+}
+
+func (d *Decoder) decodeODA(g Group) {
+	if !g.Valid(BlockD) || g.Blocks[BlockD] != AIDRTPlus {
+		return
+	}
+	// group code 0 means the application only uses 3A groups
+	if code := int(g.Blocks[BlockB] & 0x1F); code != 0 {
+		d.rtPlusGroup = code
+		d.info.RTPlus.GroupType = byte(code)
+	}
+}
+
+func (d *Decoder) decodeRTPlus(g Group) {
+	if !g.Valid(BlockC) || !g.Valid(BlockD) {
+		return
+	}
+	b, c, dd := g.Blocks[BlockB], g.Blocks[BlockC], g.Blocks[BlockD]
+	d.info.RTPlus = pira.RTPlus{
+		GroupType: byte(d.rtPlusGroup),
+		Status:    byte(b) & (pira.RTPlusStatusToggle | pira.RTPlusStatusRunning),
+		Item1: pira.RTPlusItem{
+			Type:   byte(b&0x07)<<3 | byte(c>>13),
+			Start:  byte(c>>7) & 0x3F,
+			Length: byte(c>>1) & 0x3F,
+		},
+		Item2: pira.RTPlusItem{
+			Type:   byte(c&0x01)<<5 | byte(dd>>11),
+			Start:  byte(dd>>5) & 0x3F,
+			Length: byte(dd) & 0x1F,
+		},
+	}
+}
+
+func (d *Decoder) decodeCT(g Group) {
+	if !g.Valid(BlockC) || !g.Valid(BlockD) {
+		return
+	}
+	b, c, dd := g.Blocks[BlockB], g.Blocks[BlockC], g.Blocks[BlockD]
+	mjd := uint32(b&0x03)<<15 | uint32(c>>1)
+	d.info.CT = pira.RDSCT{
+		Hour:            byte(c&0x01)<<4 | byte(dd>>12),
+		Minute:          byte(dd>>6) & 0x3F,
+		LocalTimeOffset: byte(dd) & 0x3F,
+		MJD:             pira.MJD{byte(mjd), byte(mjd >> 8), byte(mjd >> 16)},
+	}
+	d.info.Status.CT = true
+}
+
+func (d *Decoder) decodePTYN(g Group) {
+	b := g.Blocks[BlockB]
+	ab := int(b>>4) & 0x01
+	if ab != d.ptynAB {
+		d.ptyn = [8]byte(bytes.Repeat([]byte{' '}, 8))
+		d.ptynAB = ab
+	}
+	segment := int(b&0x01) * 4
+	if g.Valid(BlockC) {
+		putChars(d.ptyn[segment:], g.Blocks[BlockC])
+	}
+	if g.Valid(BlockD) {
+		putChars(d.ptyn[segment+2:], g.Blocks[BlockD])
+	}
+}
+
+func (d *Decoder) decodeEON(g Group) {
+	if !g.Valid(BlockD) {
+		return
+	}
+	pi := pira.PI(g.Blocks[BlockD])
+	if pi == 0 || pi == d.info.PI {
+		return
+	}
+	for _, known := range d.eon {
+		if known == pi {
+			return
+		}
+	}
+	if len(d.eon) < len(d.info.EONPI) {
+		d.eon = append(d.eon, pi)
+	}
+}
+
+func (d *Decoder) decodeLongPS(g Group) {
+	segment := int(g.Blocks[BlockB]&0x07) * 4
+	if g.Valid(BlockC) {
+		putChars(d.longPS[segment:], g.Blocks[BlockC])
+	}
+	if g.Valid(BlockD) {
+		putChars(d.longPS[segment+2:], g.Blocks[BlockD])
+	}
+}
+
+func putChars(dst []byte, block uint16) {
+	dst[0] = byte(block >> 8)
+	dst[1] = byte(block)
+}
+
+// Info returns the decoded state
+func (d *Decoder) Info() pira.RDSInfo {
+	info := d.info
+	info.PS = string(d.ps[:])
+	rt := d.rt[:]
+	if d.rtEnd >= 0 {
+		rt = rt[:d.rtEnd]
+	}
+	if d.info.Status.RT {
+		info.RT = string(bytes.TrimRight(rt, " "))
+	}
+	if d.ptynAB >= 0 {
+		info.PTYN = string(d.ptyn[:])
+	}
+	longPS, _, _ := bytes.Cut(d.longPS[:], []byte{'\r'})
+	info.LongPS = string(bytes.TrimRight(longPS, "\x00 "))
+	copy(info.AFList[:], d.afs)
+	info.Status.AF = len(d.afs) > 0
+	copy(info.EONPI[:], d.eon)
+	info.PIInfo = info.PI.Decode(info.ECC, d.region)
+	return info
+}
@@ -0,0 +1,257 @@
+package rds
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go-pira/pkg/pira"
+)
+
+const testPI = 0xC201
+
+func blockB(groupType int, b bool, tp bool, pty byte, low uint16) uint16 {
+	v := uint16(groupType)<<12 | uint16(pty&0x1F)<<5 | low&0x1F
+	if b {
+		v |= 0x0800
+	}
+	if tp {
+		v |= 0x0400
+	}
+	return v
+}
+
+func chars(s string, i int) uint16 {
+	return uint16(s[i])<<8 | uint16(s[i+1])
+}
+
+// psGroups returns the four 0A groups of ps with di bits and two AF codes per group
+func psGroups(ps string, di byte, afs []byte) []Group {
+	groups := make([]Group, 0, 4)
+	for seg := range 4 {
+		low := uint16(seg) | 0x0010 | 0x0008 // TA, music
+		if di&(1<<(3-seg)) != 0 {
+			low |= 0x0004
+		}
+		c := uint16(afFiller)<<8 | afFiller
+		if 2*seg+1 < len(afs) {
+			c = uint16(afs[2*seg])<<8 | uint16(afs[2*seg+1])
+		}
+		groups = append(groups, NewGroup(testPI, blockB(0, false, true, 10, low), c, chars(ps, seg*2)))
+	}
+	return groups
+}
+
+func rtGroups(rt string, ab uint16) []Group {
+	for len(rt)%4 != 0 {
+		rt += " "
+	}
+	groups := make([]Group, 0, len(rt)/4)
+	for seg := 0; seg*4 < len(rt); seg++ {
+		groups = append(groups, NewGroup(testPI, blockB(2, false, true, 10, ab<<4|uint16(seg)),
+			chars(rt, seg*4), chars(rt, seg*4+2)))
+	}
+	return groups
+}
+
+func decodeAll(t *testing.T, d *Decoder, groups ...Group) {
+	t.Helper()
+	for _, g := range groups {
+		if err := d.Decode(g); err != nil {
+			t.Fatalf("Decoder.Decode(%v) error = %v", g, err)
+		}
+	}
+}
+
+func TestDecoder_Basic(t *testing.T) {
+	d := NewDecoder(pira.RegionRDS)
+	decodeAll(t, d, psGroups("RADIO 1 ", 0b1001, []byte{0xE2, 10, 125, afFiller})...)
+
+	info := d.Info()
+	if info.PI != testPI {
+		t.Errorf("PI = %v, want %04X", info.PI, testPI)
+	}
+	if info.PS != "RADIO 1 " {
+		t.Errorf("PS = %q, want %q", info.PS, "RADIO 1 ")
+	}
+	if info.PTY.ShortName() != "Pop M" {
+		t.Errorf("PTY = %v, want Pop M", info.PTY.ShortName())
+	}
+	if !info.Status.TP || !info.Status.TA || !info.Status.MS || !info.Status.AF {
+		t.Errorf("Status = %+v, want TP, TA, MS and AF", info.Status)
+	}
+	if info.Status.DI != 0b1001 {
+		t.Errorf("DI = %04b, want 1001", info.Status.DI)
+	}
+	if info.AFList[0] != 88.5 || info.AFList[1] != 100 || info.AFList[2] != 0 {
+		t.Errorf("AFList = %v, want [88.5 100 ...]", info.AFList[:3])
+	}
+	if info.Groups[0] != 4 {
+		t.Errorf("Groups[0A] = %v, want 4", info.Groups[0])
+	}
+}
+
+func TestDecoder_AFLFMF(t *testing.T) {
+	d := NewDecoder(pira.RegionRDS)
+	// 250 marks the following code, 10, as an LF/MF frequency
+	decodeAll(t, d, psGroups("RADIO 1 ", 0, []byte{0xE3, 125, afLFMF, 10, 15, afFiller})...)
+
+	info := d.Info()
+	if got := info.AFList; got[0] != 100 || got[1] != 89 || got[2] != 0 {
+		t.Errorf("AFList = %v, want [100 89 ...]", got[:3])
+	}
+}
+
+func TestDecoder_PartialPS(t *testing.T) {
+	d := NewDecoder(pira.RegionRDS)
+	groups := psGroups("RADIO 1 ", 0, nil)
+	groups[1].Errors[BlockD] = true
+	decodeAll(t, d, groups...)
+
+	if got := d.Info().PS; got != "RA  O 1 " {
+		t.Errorf("PS = %q, want %q", got, "RA  O 1 ")
+	}
+}
+
+func TestDecoder_RadioText(t *testing.T) {
+	d := NewDecoder(pira.RegionRDS)
+	decodeAll(t, d, rtGroups("Hello world\r", 0)...)
+	if got := d.Info().RT; got != "Hello world" {
+		t.Errorf("RT = %q, want %q", got, "Hello world")
+	}
+
+	// a new text with the other A/B flag replaces the old one entirely
+	decodeAll(t, d, rtGroups("Bye", 1)...)
+	info := d.Info()
+	if info.RT != "Bye" {
+		t.Errorf("RT = %q, want %q", info.RT, "Bye")
+	}
+	if info.Status.RTType != pira.RTTypeB {
+		t.Errorf("RTType = %v, want %v", info.Status.RTType, pira.RTTypeB)
+	}
+}
+
+func TestDecoder_RadioText2B(t *testing.T) {
+	d := NewDecoder(pira.RegionRDS)
+	for seg, s := range []string{"Hi", " t", "he", "re", "\r "} {
+		decodeAll(t, d, NewGroup(testPI, blockB(2, true, false, 0, uint16(seg)), testPI, chars(s, 0)))
+	}
+	if got := d.Info().RT; got != "Hi there" {
+		t.Errorf("RT = %q, want %q", got, "Hi there")
+	}
+}
+
+func TestDecoder_ClockTime(t *testing.T) {
+	d := NewDecoder(pira.RegionRDS)
+	mjd, hour, minute, offset := uint32(60000), uint16(22), uint16(30), uint16(0x04)
+	g := NewGroup(testPI,
+		blockB(4, false, false, 0, uint16(mjd>>15)),
+		uint16(mjd<<1)|hour>>4,
+		hour<<12|minute<<6|offset)
+	decodeAll(t, d, g)
+
+	info := d.Info()
+	if !info.Status.CT {
+		t.Errorf("Status.CT = false, want true")
+	}
+	local, err := info.CT.Local()
+	if err != nil {
+		t.Fatalf("RDSCT.Local() error = %v", err)
+	}
+	want := time.Date(2023, 2, 25, 22, 30, 0, 0, time.UTC)
+	if !local.Equal(want) || local.Format("15:04") != "00:30" {
+		t.Errorf("RDSCT.Local() = %v, want %v at +02:00", local, want)
+	}
+}
+
+func TestDecoder_RTPlus(t *testing.T) {
+	d := NewDecoder(pira.RegionRDS)
+	const rt = "Queen - Bohemian Rhapsody"
+	// RT+ announced on group 11A
+	oda := NewGroup(testPI, blockB(3, false, false, 0, 11<<1), 0x0000, AIDRTPlus)
+	// artist at 0 (length 5), title at 8 (length 17), running with toggle
+	type1, start1, len1 := uint16(4), uint16(0), uint16(4)
+	type2, start2, len2 := uint16(1), uint16(8), uint16(16)
+	rtPlus := NewGroup(testPI,
+		blockB(11, false, false, 0, 0x10|0x08|type1>>3),
+		type1<<13|start1<<7|len1<<1|type2>>5,
+		type2<<11|start2<<5|len2)
+	decodeAll(t, d, rtGroups(rt+"\r", 0)...)
+	decodeAll(t, d, oda, rtPlus)
+
+	info := d.Info()
+	if info.RTPlus.GroupType != 22 {
+		t.Errorf("RTPlus.GroupType = %v, want 22", info.RTPlus.GroupType)
+	}
+	want := pira.NowPlaying{Artist: "Queen", Title: "Bohemian Rhapsody", Running: true, Toggle: true}
+	if got := info.NowPlaying(); got != want {
+		t.Errorf("NowPlaying() = %+v, want %+v", got, want)
+	}
+	if info.Groups[22] != 1 {
+		t.Errorf("Groups[11A] = %v, want 1", info.Groups[22])
+	}
+}
+
+func TestDecoder_SlowLabellingAndOthers(t *testing.T) {
+	d := NewDecoder(pira.RegionRDS)
+	pin := uint16(15)<<11 | uint16(8)<<6 | 30
+	ptyn := "Hits 24h"
+	longPS := "Radio Uno\r"
+	decodeAll(t, d,
+		NewGroup(testPI, blockB(1, false, false, 0, 0), 0x00E3, pin),
+		NewGroup(testPI, blockB(1, false, false, 0, 0), 0x3000|0x04, pin),
+		NewGroup(testPI, blockB(10, false, false, 0, 0), chars(ptyn, 0), chars(ptyn, 2)),
+		NewGroup(testPI, blockB(10, false, false, 0, 1), chars(ptyn, 4), chars(ptyn, 6)),
+		NewGroup(testPI, blockB(14, false, false, 0, 0), 0x0000, 0xC202),
+		NewGroup(testPI, blockB(14, true, false, 0, 0), testPI, 0xC203),
+		NewGroup(testPI, blockB(14, false, false, 0, 0), 0x0000, 0xC202),
+		NewGroup(testPI, blockB(15, false, false, 0, 0), chars(longPS, 0), chars(longPS, 2)),
+		NewGroup(testPI, blockB(15, false, false, 0, 1), chars(longPS, 4), chars(longPS, 6)),
+		NewGroup(testPI, blockB(15, false, false, 0, 2), chars(longPS+"  ", 8), 0x2020),
+	)
+
+	info := d.Info()
+	if info.ECC != 0xE3 || info.PIInfo.Country != "HR" {
+		t.Errorf("ECC = %X country %q, want E3 HR", info.ECC, info.PIInfo.Country)
+	}
+	if info.LIC != 0x04 {
+		t.Errorf("LIC = %X, want 04", info.LIC)
+	}
+	if info.PIN != (pira.RDSPIN{Day: 15, Hour: 8, Minute: 30}) {
+		t.Errorf("PIN = %v, want 15 08:30", info.PIN)
+	}
+	if info.PTYN != ptyn {
+		t.Errorf("PTYN = %q, want %q", info.PTYN, ptyn)
+	}
+	if info.EONPI != [4]pira.PI{0xC202, 0xC203} {
+		t.Errorf("EONPI = %v, want [C202 C203 0000 0000]", info.EONPI)
+	}
+	if info.LongPS != "Radio Uno" {
+		t.Errorf("LongPS = %q, want %q", info.LongPS, "Radio Uno")
+	}
+}
+
+func TestDecoder_Errors(t *testing.T) {
+	d := NewDecoder(pira.RegionRDS)
+
+	g := NewGroup(testPI, blockB(0, false, false, 0, 0), 0, chars("AB", 0))
+	g.Errors[BlockB] = true
+	if err := d.Decode(g); !errors.Is(err, ErrNoBlockB) {
+		t.Errorf("Decoder.Decode() error = %v, want %v", err, ErrNoBlockB)
+	}
+
+	// PI is taken from block C' of version B groups when block A is lost
+	g = NewGroup(0, blockB(0, true, false, 0, 0), testPI, chars("AB", 0))
+	g.Errors[BlockA] = true
+	decodeAll(t, d, g)
+	if d.Info().PI != testPI {
+		t.Errorf("PI = %v, want %04X", d.Info().PI, testPI)
+	}
+
+	// a different PI means a different station
+	decodeAll(t, d, NewGroup(0xC202, blockB(0, false, false, 0, 1), 0, chars("CD", 0)))
+	info := d.Info()
+	if info.PI != 0xC202 || info.PS != "  CD    " {
+		t.Errorf("after PI change PI = %v PS = %q, want C202 %q", info.PI, info.PS, "  CD    ")
+	}
+}
@@ -0,0 +1,78 @@
+package rds
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNoBlockB is returned for groups whose type cannot be decoded
+var ErrNoBlockB = errors.New("rds: block B not received")
+
+// Block indexes within a group
+const (
+	BlockA = 0
+	BlockB = 1
+	BlockC = 2
+	BlockD = 3
+)
+
+// Group is a raw RDS group of four 16 bit blocks
+type Group struct {
+	Blocks [4]uint16
+	// Errors marks blocks that were not received or failed error correction
+	Errors [4]bool
+}
+
+// NewGroup returns a group with all four blocks received
+func NewGroup(a, b, c, d uint16) Group {
+	return Group{Blocks: [4]uint16{a, b, c, d}}
+}
+
+// Valid reports whether block i was received without errors
+func (g Group) Valid(i int) bool {
+	return !g.Errors[i]
+}
+
+// Type returns the group type code 0 .. 15
+func (g Group) Type() int {
+	return int(g.Blocks[BlockB] >> 12)
+}
+
+// IsB reports whether the group is a version B group
+func (g Group) IsB() bool {
+	return g.Blocks[BlockB]&0x0800 != 0
+}
+
+// Index returns the group position in the analyzer counter layout,
+// type * 2 plus one for version B
+func (g Group) Index() int {
+	index := g.Type() * 2
+	if g.IsB() {
+		index++
+	}
+	return index
+}
+
+// Name returns the group type label, e.g. "0A"
+func (g Group) Name() string {
+	version := 'A'
+	if g.IsB() {
+		version = 'B'
+	}
+	return fmt.Sprintf("%d%c", g.Type(), version)
+}
+
+func (g Group) String() string {
+	s := ""
+	for i, block := range g.Blocks {
+		if i > 0 {
+			s += " "
+		}
+		if g.Errors[i] {
+			s += "----"
+		} else {
+			s += fmt.Sprintf("%04X", block)
+		}
+	}
+	return s
+}
@@ -0,0 +1,217 @@
+package rds
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"iter"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-pira/pkg/pira"
+)
+
+// Format is a group log file format
+type Format int
+
+const (
+	// FormatHex is one group per line as four hex blocks, "----" marks a lost block
+	FormatHex Format = iota
+	// FormatSpy is the RDS Spy log, hex blocks followed by an "@" timestamp
+	FormatSpy
+)
+
+func (f Format) String() string {
+	if f == FormatSpy {
+		return "spy"
+	}
+	return "hex"
+}
+
+// ParseFormat parses "hex" or "spy"
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "hex", "txt":
+		return FormatHex, nil
+	case "spy":
+		return FormatSpy, nil
+	}
+	return FormatHex, fmt.Errorf("unknown log format: %s", s)
+}
+
+// FormatFromPath guesses the format from the file extension
+func FormatFromPath(path string) Format {
+	if strings.EqualFold(filepath.Ext(path), ".spy") {
+		return FormatSpy
+	}
+	return FormatHex
+}
+
+const spyTimeLayout = "2006/01/02 15:04:05.00"
+
+// Record is a logged group, Time is zero when the log has no timestamps
+type Record struct {
+	Time  time.Time
+	Group Group
+}
+
+// Reader reads groups from hex and RDS Spy logs, both formats are
+// accepted and lines that are not groups are skipped
+type Reader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+// NewReader returns a log reader
+func NewReader(r io.Reader) *Reader {
+	return &Reader{scanner: bufio.NewScanner(r)}
+}
+
+// Read returns the next group record or io.EOF
+func (r *Reader) Read() (Record, error) {
+	for r.scanner.Scan() {
+		r.line++
+		line := strings.TrimSpace(r.scanner.Text())
+		if line == "" || strings.ContainsAny(line[:1], "<#%;") {
+			continue
+		}
+		rec, err := parseRecord(line)
+		if err != nil {
+			return Record{}, fmt.Errorf("line %d: %w", r.line, err)
+		}
+		return rec, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return Record{}, err
+	}
+	return Record{}, io.EOF
+}
+
+// All iterates over the remaining records
+func (r *Reader) All() iter.Seq2[Record, error] {
+	return func(yield func(Record, error) bool) {
+		for {
+			rec, err := r.Read()
+			if err == io.EOF {
+				return
+			}
+			if !yield(rec, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+func parseRecord(line string) (Record, error) {
+	var rec Record
+	blocks, stamp, hasStamp := strings.Cut(line, "@")
+	if hasStamp {
+		t, err := time.ParseInLocation(spyTimeLayout, strings.TrimSpace(stamp), time.UTC)
+		if err != nil {
+			return rec, fmt.Errorf("invalid timestamp: %s", stamp)
+		}
+		rec.Time = t
+	}
+
+	fields := strings.Fields(blocks)
+	if len(fields) == 1 && len(fields[0]) == 16 {
+		s := fields[0]
+		fields = []string{s[0:4], s[4:8], s[8:12], s[12:16]}
+	}
+	if len(fields) != 4 {
+		return rec, fmt.Errorf("invalid group: %s", line)
+	}
+	for i, field := range fields {
+		if strings.Trim(field, "-") == "" {
+			rec.Group.Errors[i] = true
+			continue
+		}
+		v, err := strconv.ParseUint(field, 16, 16)
+		if err != nil {
+			return rec, fmt.Errorf("invalid block: %s", field)
+		}
+		rec.Group.Blocks[i] = uint16(v)
+	}
+	return rec, nil
+}
+
+// Writer writes groups as a hex or RDS Spy log
+type Writer struct {
+	w      *bufio.Writer
+	format Format
+	header bool
+}
+
+// NewWriter returns a log writer, Flush must be called when done
+func NewWriter(w io.Writer, format Format) *Writer {
+	return &Writer{w: bufio.NewWriter(w), format: format}
+}
+
+// Write appends a record, RDS Spy logs use the current time when the
+// record has no timestamp
+func (w *Writer) Write(rec Record) error {
+	if w.format == FormatHex {
+		_, err := fmt.Fprintln(w.w, rec.Group.String())
+		return err
+	}
+	t := rec.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	t = t.UTC()
+	if !w.header {
+		_, err := fmt.Fprintf(w.w, "<recorder=\"go-pira\" date=\"%s\" time=\"%s\">\n",
+			t.Format("2006-01-02"), t.Format("15:04:05"))
+		if err != nil {
+			return err
+		}
+		w.header = true
+	}
+	_, err := fmt.Fprintf(w.w, "%s @%s\n", rec.Group.String(), t.Format(spyTimeLayout))
+	return err
+}
+
+// Flush writes buffered data to the underlying writer
+func (w *Writer) Flush() error {
+	return w.w.Flush()
+}
+
+// TimelineEntry is the decoded state after a group changed it
+type TimelineEntry struct {
+	Time  time.Time    `json:"time,omitzero"`
+	Index int          `json:"index"`
+	Group string       `json:"group"`
+	Info  pira.RDSInfo `json:"info"`
+}
+
+// Timeline feeds the records of r through d and yields the decoded state
+// every time it changes, group counters alone do not count as a change
+func Timeline(r *Reader, d *Decoder) iter.Seq2[TimelineEntry, error] {
+	return func(yield func(TimelineEntry, error) bool) {
+		var last pira.RDSInfo
+		index := 0
+		for rec, err := range r.All() {
+			if err != nil {
+				yield(TimelineEntry{}, err)
+				return
+			}
+			index++
+			if d.Decode(rec.Group) != nil {
+				continue
+			}
+			info := d.Info()
+			current := info
+			current.Groups = [32]byte{}
+			if current == last {
+				continue
+			}
+			last = current
+			entry := TimelineEntry{Time: rec.Time, Index: index, Group: rec.Group.Name(), Info: info}
+			if !yield(entry, nil) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package rds
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"go-pira/pkg/pira"
+)
+
+func TestReader_Read(t *testing.T) {
+	input := `<recorder="RDS Spy" date="2024-01-15" time="12:00:00">
+C201 0408 E20D 5241 @2024/01/15 12:00:00.25
+C201 0409 ---- 4449 @2024/01/15 12:00:00.34
+
+% comment
+C2010408E20D5241
+</recorder>
+`
+	r := NewReader(strings.NewReader(input))
+	var records []Record
+	for rec, err := range r.All() {
+		if err != nil {
+			t.Fatalf("Reader.All() error = %v", err)
+		}
+		records = append(records, rec)
+	}
+	if len(records) != 3 {
+		t.Fatalf("Reader.All() = %d records, want 3", len(records))
+	}
+	if want := time.Date(2024, 1, 15, 12, 0, 0, 250_000_000, time.UTC); !records[0].Time.Equal(want) {
+		t.Errorf("record 0 time = %v, want %v", records[0].Time, want)
+	}
+	if records[1].Group.Valid(BlockC) || records[1].Group.Blocks[BlockD] != 0x4449 {
+		t.Errorf("record 1 = %v, want lost block C", records[1].Group)
+	}
+	if records[2].Group != records[0].Group || !records[2].Time.IsZero() {
+		t.Errorf("record 2 = %v, want %v without time", records[2].Group, records[0].Group)
+	}
+}
+
+func TestReader_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "too few blocks", input: "C201 0408 E20D\n"},
+		{name: "not hex", input: "C201 0408 E20D XYZW\n"},
+		{name: "bad timestamp", input: "C201 0408 E20D 5241 @yesterday\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewReader(strings.NewReader(tt.input)).Read(); err == nil {
+				t.Errorf("Reader.Read() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestWriter_RoundTrip(t *testing.T) {
+	at := time.Date(2024, 1, 15, 12, 0, 0, 500_000_000, time.UTC)
+	lost := NewGroup(0xC201, 0x0409, 0, 0x4449)
+	lost.Errors[BlockC] = true
+	records := []Record{
+		{Time: at, Group: NewGroup(0xC201, 0x0408, 0xE20D, 0x5241)},
+		{Time: at.Add(87 * time.Millisecond), Group: lost},
+	}
+
+	for _, format := range []Format{FormatHex, FormatSpy} {
+		t.Run(format.String(), func(t *testing.T) {
+			var buf bytes.Buffer
+			w := NewWriter(&buf, format)
+			for _, rec := range records {
+				if err := w.Write(rec); err != nil {
+					t.Fatalf("Writer.Write() error = %v", err)
+				}
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("Writer.Flush() error = %v", err)
+			}
+
+			r := NewReader(&buf)
+			for i, want := range records {
+				got, err := r.Read()
+				if err != nil {
+					t.Fatalf("Reader.Read() error = %v", err)
+				}
+				if got.Group != want.Group {
+					t.Errorf("record %d group = %v, want %v", i, got.Group, want.Group)
+				}
+				if format == FormatSpy && !got.Time.Equal(want.Time.Truncate(10*time.Millisecond)) {
+					t.Errorf("record %d time = %v, want %v", i, got.Time, want.Time)
+				}
+			}
+		})
+	}
+}
+
+func TestTimeline(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, FormatHex)
+	groups := psGroups("RADIO 1 ", 0, nil)
+	// the same PS again only bumps the group counters
+	groups = append(groups, psGroups("RADIO 1 ", 0, nil)...)
+	for _, g := range groups {
+		if err := w.Write(Record{Group: g}); err != nil {
+			t.Fatalf("Writer.Write() error = %v", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Writer.Flush() error = %v", err)
+	}
+
+	var entries []TimelineEntry
+	for entry, err := range Timeline(NewReader(&buf), NewDecoder(pira.RegionRDS)) {
+		if err != nil {
+			t.Fatalf("Timeline() error = %v", err)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("Timeline() = %d entries, want 4", len(entries))
+	}
+	if last := entries[len(entries)-1]; last.Index != 4 || last.Info.PS != "RADIO 1 " {
+		t.Errorf("last entry = %d %q, want 4 %q", last.Index, last.Info.PS, "RADIO 1 ")
+	}
+}
@@ -0,0 +1,333 @@
+package record
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileOptions configure a rotating log file
+type FileOptions struct {
+	// Path is the active log file, rotated files are renamed next to it
+	// with the time they were started, e.g. survey-20250101T120000.csv
+	Path string
+	// MaxSize rotates the file before it grows beyond this many bytes,
+	// zero disables size rotation
+	MaxSize int64
+	// MaxAge rotates the file once it was started this long ago, zero
+	// disables time rotation
+	MaxAge time.Duration
+	// Compress gzips rotated files in the background
+	Compress bool
+	// SyncInterval is the longest time written data stays unsynced,
+	// defaults to 2s
+	SyncInterval time.Duration
+	// Header is written at the start of every new file
+	Header []byte
+	// RecordTime parses the time of a record line. It dates an existing
+	// file from its first record when the log is reopened, the file's
+	// modification time is used when it is nil or fails.
+	RecordTime func(line []byte) (time.Time, error)
+}
+
+// File is an append only log file that rotates by size and age and
+// fsyncs periodically. It is safe for concurrent use.
+type File struct {
+	opts FileOptions
+	now  func() time.Time
+
+	mu      sync.Mutex
+	f       *os.File
+	size    int64
+	started time.Time
+	dirty   bool
+	closed  bool
+
+	done     chan struct{}
+	compress sync.WaitGroup
+}
+
+// OpenFile opens or creates the log file, appending to an existing one
+func OpenFile(opts FileOptions) (*File, error) {
+	if opts.SyncInterval <= 0 {
+		opts.SyncInterval = 2 * time.Second
+	}
+	f := &File{opts: opts, now: time.Now, done: make(chan struct{})}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	if opts.Compress {
+		f.compressLeftovers()
+	}
+	go f.syncLoop()
+	return f, nil
+}
+
+// open opens the active file, the caller holds mu or owns f
+func (f *File) open() error {
+	file, err := os.OpenFile(f.opts.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	size, started := info.Size(), f.now()
+	if size > int64(len(f.opts.Header)) {
+		started = f.firstRecordTime(info)
+	}
+	if size == 0 && len(f.opts.Header) > 0 {
+		n, err := file.Write(f.opts.Header)
+		size += int64(n)
+		if err != nil {
+			file.Close()
+			return err
+		}
+		f.dirty = true
+	}
+	f.f, f.size, f.started = file, size, started
+	return nil
+}
+
+// firstRecordTime dates an existing active file by its first record,
+// falling back to its modification time
+func (f *File) firstRecordTime(info os.FileInfo) time.Time {
+	if f.opts.RecordTime == nil {
+		return info.ModTime()
+	}
+	file, err := os.Open(f.opts.Path)
+	if err != nil {
+		return info.ModTime()
+	}
+	defer file.Close()
+	if _, err := file.Seek(int64(len(f.opts.Header)), io.SeekStart); err != nil {
+		return info.ModTime()
+	}
+	line, err := bufio.NewReader(file).ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return info.ModTime()
+	}
+	t, err := f.opts.RecordTime(bytes.TrimSpace(line))
+	if err != nil {
+		return info.ModTime()
+	}
+	return t
+}
+
+// Write appends p, rotating first when p would exceed MaxSize or the
+// file is older than MaxAge
+func (f *File) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+	if f.due(int64(len(p))) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.f.Write(p)
+	f.size += int64(n)
+	f.dirty = true
+	return n, err
+}
+
+// due reports whether writing n more bytes needs a rotation, a file that
+// holds nothing but the header is never rotated
+func (f *File) due(n int64) bool {
+	if f.size <= int64(len(f.opts.Header)) {
+		return false
+	}
+	if f.opts.MaxSize > 0 && f.size+n > f.opts.MaxSize {
+		return true
+	}
+	return f.opts.MaxAge > 0 && f.now().Sub(f.started) >= f.opts.MaxAge
+}
+
+// Rotate closes the active file and starts a new one
+func (f *File) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return os.ErrClosed
+	}
+	return f.rotate()
+}
+
+// rotate renames the active file and opens a new one. The old handle
+// is only closed once the new file is open, on failure the old file is
+// moved back and kept so later writes still land in it.
+func (f *File) rotate() error {
+	if err := f.f.Sync(); err != nil {
+		return err
+	}
+	old, dirty := f.f, f.dirty
+	rotated := f.rotatedName()
+	if err := os.Rename(f.opts.Path, rotated); err != nil {
+		return err
+	}
+	f.dirty = false
+	if err := f.open(); err != nil {
+		f.dirty = dirty
+		if rerr := os.Rename(rotated, f.opts.Path); rerr != nil {
+			slog.Warn("failed to restore log", "file", rotated, "error", rerr)
+		}
+		return err
+	}
+	if err := old.Close(); err != nil {
+		slog.Warn("failed to close log", "file", rotated, "error", err)
+	}
+	if f.opts.Compress {
+		f.compressAsync(rotated)
+	}
+	return nil
+}
+
+// compressAsync gzips a rotated file in the background
+func (f *File) compressAsync(name string) {
+	f.compress.Add(1)
+	go func() {
+		defer f.compress.Done()
+		if err := compressFile(name); err != nil {
+			slog.Warn("failed to compress log", "file", name, "error", err)
+		}
+	}()
+}
+
+// compressLeftovers compresses rotated files a crash left uncompressed.
+// A .gz next to one is a partial compression and is replaced.
+func (f *File) compressLeftovers() {
+	ext := filepath.Ext(f.opts.Path)
+	base := strings.TrimSuffix(f.opts.Path, ext) + "-"
+	names, _ := filepath.Glob(base + "*" + ext)
+	for _, name := range names {
+		if !isRotatedStamp(strings.TrimSuffix(strings.TrimPrefix(name, base), ext)) {
+			continue
+		}
+		os.Remove(name + ".gz")
+		f.compressAsync(name)
+	}
+}
+
+// isRotatedStamp reports whether s is the start time of a rotated name,
+// optionally followed by a -N suffix
+func isRotatedStamp(s string) bool {
+	const layout = "20060102T150405"
+	if len(s) < len(layout) {
+		return false
+	}
+	if _, err := time.Parse(layout, s[:len(layout)]); err != nil {
+		return false
+	}
+	rest := s[len(layout):]
+	if rest == "" {
+		return true
+	}
+	n, ok := strings.CutPrefix(rest, "-")
+	_, err := strconv.Atoi(n)
+	return ok && err == nil
+}
+
+// rotatedName returns an unused name for the active file, stamped with
+// the time it was started
+func (f *File) rotatedName() string {
+	ext := filepath.Ext(f.opts.Path)
+	base := strings.TrimSuffix(f.opts.Path, ext) + "-" + f.started.Format("20060102T150405")
+	name := base + ext
+	for i := 1; exists(name) || exists(name+".gz"); i++ {
+		name = fmt.Sprintf("%s-%d%s", base, i, ext)
+	}
+	return name
+}
+
+func exists(name string) bool {
+	_, err := os.Stat(name)
+	return !errors.Is(err, os.ErrNotExist)
+}
+
+// compressFile replaces name with name.gz
+func compressFile(name string) error {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(name+".gz", os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	zw.Name = filepath.Base(name)
+	_, err = io.Copy(zw, src)
+	if err == nil {
+		err = zw.Close()
+	}
+	if err == nil {
+		err = dst.Sync()
+	}
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(name + ".gz")
+		return err
+	}
+	return os.Remove(name)
+}
+
+// Sync commits written data to stable storage
+func (f *File) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed || !f.dirty {
+		return nil
+	}
+	f.dirty = false
+	return f.f.Sync()
+}
+
+func (f *File) syncLoop() {
+	ticker := time.NewTicker(f.opts.SyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-f.done:
+			return
+		case <-ticker.C:
+			if err := f.Sync(); err != nil {
+				slog.Warn("failed to sync log", "file", f.opts.Path, "error", err)
+			}
+		}
+	}
+}
+
+// Close syncs and closes the file and waits for pending compressions
+func (f *File) Close() error {
+	f.mu.Lock()
+	if f.closed {
+		f.mu.Unlock()
+		return nil
+	}
+	f.closed = true
+	close(f.done)
+	err := f.f.Sync()
+	if cerr := f.f.Close(); err == nil {
+		err = cerr
+	}
+	f.mu.Unlock()
+	f.compress.Wait()
+	return err
+}
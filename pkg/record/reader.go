@@ -0,0 +1,70 @@
+package record
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"go-pira/pkg/pira"
+)
+
+// Reader decodes the snapshots of an NDJSON log
+type Reader struct {
+	dec  *json.Decoder
+	line int
+}
+
+// NewReader returns a reader of the NDJSON log in r
+func NewReader(r io.Reader) *Reader {
+	return &Reader{dec: json.NewDecoder(r)}
+}
+
+// Read returns the next snapshot, io.EOF at the end of the log
+func (r *Reader) Read() (pira.Snapshot, error) {
+	var s pira.Snapshot
+	r.line++
+	if err := r.dec.Decode(&s); err != nil {
+		if err == io.EOF {
+			return s, err
+		}
+		return s, fmt.Errorf("snapshot %d: %w", r.line, err)
+	}
+	return s, nil
+}
+
+// readCloser closes the gzip stream and the file under it
+type readCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (r *readCloser) Close() error {
+	var err error
+	for _, c := range r.closers {
+		if cerr := c.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// OpenLog opens an NDJSON log for reading, rotated logs compressed with
+// gzip are read through
+func OpenLog(name string) (io.ReadCloser, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(name, ".gz") {
+		return f, nil
+	}
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return &readCloser{Reader: zr, closers: []io.Closer{zr, f}}, nil
+}
@@ -0,0 +1,278 @@
+package record
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"go-pira/pkg/pira"
+)
+
+func TestWriter(t *testing.T) {
+	at := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	fmi := &pira.FMInfo{Frequency: 98500, DeviationMax: 75000, RDS: pira.RDSInfo{PI: 0xD3C2, PS: "RADIO 1 "}}
+	snapshots := []pira.Snapshot{
+		{Seq: 1, Time: at, FMInfo: fmi},
+		{Seq: 2, Time: at.Add(time.Second), Kind: pira.SnapshotFast, FMInfo: fmi},
+		{Seq: 3, Time: at.Add(2 * time.Second), Err: errors.New("read timeout")},
+	}
+
+	var buf bytes.Buffer
+	buf.Write(FormatCSV.Header())
+	w := NewWriter(&buf, FormatCSV)
+	for _, s := range snapshots {
+		if err := w.Write(s); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("lines = %d, want 4:\n%s", len(lines), buf.String())
+	}
+	if want := strings.Join(Columns(), ","); lines[0] != want {
+		t.Errorf("header = %s, want %s", lines[0], want)
+	}
+	for i, line := range lines {
+		if n := strings.Count(line, ",") + 1; n != len(Columns()) {
+			t.Errorf("line %d has %d columns, want %d", i, n, len(Columns()))
+		}
+	}
+	if !strings.HasPrefix(lines[1], "2025-01-01T12:00:00.000Z,1,slow,,98500000,0,75000,") || !strings.Contains(lines[1], ",D3C2,RADIO 1 ,") {
+		t.Errorf("slow row = %s", lines[1])
+	}
+	if strings.Contains(lines[2], "D3C2") {
+		t.Errorf("fast row carries RDS: %s", lines[2])
+	}
+	if !strings.HasPrefix(lines[3], "2025-01-01T12:00:02.000Z,3,slow,read timeout,,") {
+		t.Errorf("error row = %s", lines[3])
+	}
+
+	buf.Reset()
+	w = NewWriter(&buf, FormatNDJSON)
+	w.Write(snapshots[2])
+	if got := buf.String(); !strings.HasPrefix(got, `{"seq":3,`) || !strings.HasSuffix(got, "\"error\":\"read timeout\"}\n") {
+		t.Errorf("ndjson line = %s", got)
+	}
+}
+
+func TestReader(t *testing.T) {
+	at := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	fmi := &pira.FMInfo{Frequency: 98500, DeviationMax: 75000, RDS: pira.RDSInfo{PI: 0xD3C2, PS: "RADIO 1 "}}
+	snapshots := []pira.Snapshot{
+		{Seq: 1, Time: at, FMInfo: fmi},
+		{Seq: 2, Time: at.Add(time.Second), Err: errors.New("read timeout")},
+	}
+	name := filepath.Join(t.TempDir(), "survey.ndjson.gz")
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := gzip.NewWriter(f)
+	w := NewWriter(zw, FormatNDJSON)
+	for _, s := range snapshots {
+		w.Write(s)
+	}
+	zw.Close()
+	f.Close()
+
+	log, err := OpenLog(name)
+	if err != nil {
+		t.Fatalf("OpenLog() error = %v", err)
+	}
+	defer log.Close()
+	r := NewReader(log)
+	for _, want := range snapshots {
+		got, err := r.Read()
+		if err != nil {
+			t.Fatalf("Read() error = %v", err)
+		}
+		if got.Seq != want.Seq || !got.Time.Equal(want.Time) || (got.Err == nil) != (want.Err == nil) {
+			t.Errorf("Read() = %+v, want %+v", got, want)
+		}
+		if want.FMInfo != nil && (got.FMInfo == nil || got.FMInfo.Frequency != 98500 || got.FMInfo.RDS.PI != 0xD3C2) {
+			t.Errorf("Read() FMInfo = %+v", got.FMInfo)
+		}
+	}
+	if _, err := r.Read(); err != io.EOF {
+		t.Errorf("Read() at the end error = %v, want io.EOF", err)
+	}
+}
+
+func TestColumns_Stable(t *testing.T) {
+	// columns may only be appended, existing logs depend on the order
+	prefix := []string{"time", "seq", "kind", "error", "fm.frequency", "fm.deviation", "fm.deviation_max"}
+	if got := Columns(); !slices.Equal(got[:len(prefix)], prefix) {
+		t.Errorf("Columns() = %v", got)
+	}
+}
+
+func TestFile_Rotate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "survey.csv")
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	header := []byte("a,b\n")
+
+	f, err := OpenFile(FileOptions{Path: path, MaxSize: 20, MaxAge: time.Hour, Compress: true, Header: header})
+	if err != nil {
+		t.Fatalf("OpenFile() error = %v", err)
+	}
+	f.now = func() time.Time { return now }
+	f.started = now
+
+	// 4 byte header plus two 8 byte lines fit, the third one rotates
+	for range 3 {
+		if _, err := f.Write([]byte("1234567\n")); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	// an hour later the age rotates, the name gets a suffix
+	f.Write([]byte("1234567\n"))
+	now = now.Add(time.Hour)
+	f.Write([]byte("1234567\n"))
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if _, err := f.Write([]byte("x\n")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Write() after Close error = %v", err)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	want := []string{"survey-20250101T120000-1.csv.gz", "survey-20250101T120000.csv.gz", "survey.csv"}
+	if !slices.Equal(names, want) {
+		t.Fatalf("files = %v, want %v", names, want)
+	}
+	contents := map[string]string{
+		"survey-20250101T120000.csv.gz":   "a,b\n1234567\n1234567\n",
+		"survey-20250101T120000-1.csv.gz": "a,b\n1234567\n1234567\n",
+		"survey.csv":                      "a,b\n1234567\n",
+	}
+	for name, want := range contents {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.HasSuffix(name, ".gz") {
+			zr, err := gzip.NewReader(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			data, _ = io.ReadAll(zr)
+		}
+		if string(data) != want {
+			t.Errorf("%s = %q, want %q", name, data, want)
+		}
+	}
+}
+
+func TestFile_Append(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "survey.csv")
+	for range 2 {
+		f, err := OpenFile(FileOptions{Path: path, Header: []byte("a\n")})
+		if err != nil {
+			t.Fatalf("OpenFile() error = %v", err)
+		}
+		f.Write([]byte("1\n"))
+		f.Close()
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "a\n1\n1\n" {
+		t.Errorf("file = %q, want the header once", data)
+	}
+}
+
+func TestFile_RotateFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "survey.csv")
+	f, err := OpenFile(FileOptions{Path: path})
+	if err != nil {
+		t.Fatalf("OpenFile() error = %v", err)
+	}
+	defer f.Close()
+	f.Write([]byte("1\n"))
+
+	// the rename fails, the open handle is kept for later writes
+	os.Remove(path)
+	if err := f.Rotate(); err == nil {
+		t.Fatal("Rotate() error = nil")
+	}
+	if _, err := f.Write([]byte("2\n")); err != nil {
+		t.Errorf("Write() after failed rotation error = %v", err)
+	}
+	if err := f.Sync(); err != nil {
+		t.Errorf("Sync() after failed rotation error = %v", err)
+	}
+}
+
+func TestFile_Reopen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "survey.csv")
+	header := FormatCSV.Header()
+	started := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	data := append(slices.Clip(header), "2025-01-01T12:00:00.000Z,1\n"...)
+	os.WriteFile(path, data, 0o644)
+	// a crash left a rotated file uncompressed and a partial .gz of it
+	leftover := filepath.Join(dir, "survey-20241231T120000.csv")
+	os.WriteFile(leftover, []byte("old\n"), 0o644)
+	os.WriteFile(leftover+".gz", []byte("partial"), 0o644)
+	os.WriteFile(filepath.Join(dir, "survey-notes.csv"), nil, 0o644)
+
+	f, err := OpenFile(FileOptions{Path: path, MaxAge: time.Hour, Compress: true, Header: header, RecordTime: FormatCSV.RecordTime})
+	if err != nil {
+		t.Fatalf("OpenFile() error = %v", err)
+	}
+	if !f.started.Equal(started) {
+		t.Errorf("started = %v, want the first record %v", f.started, started)
+	}
+	f.now = func() time.Time { return started.Add(time.Hour) }
+	f.Write([]byte("2025-01-01T13:00:00.000Z,2\n"))
+	f.Close()
+
+	entries, _ := os.ReadDir(dir)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	want := []string{"survey-20241231T120000.csv.gz", "survey-20250101T120000.csv.gz", "survey-notes.csv", "survey.csv"}
+	if !slices.Equal(names, want) {
+		t.Fatalf("files = %v, want %v", names, want)
+	}
+	zf, _ := os.Open(leftover + ".gz")
+	defer zf.Close()
+	zr, err := gzip.NewReader(zf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := io.ReadAll(zr); string(got) != "old\n" {
+		t.Errorf("leftover = %q, want %q", got, "old\n")
+	}
+}
+
+func TestFormat_RecordTime(t *testing.T) {
+	want := time.Date(2025, 1, 1, 12, 0, 0, 500e6, time.UTC)
+	tests := []struct {
+		format Format
+		line   string
+	}{
+		{FormatCSV, "2025-01-01T12:00:00.500Z,1,slow"},
+		{FormatNDJSON, `{"time":"2025-01-01T12:00:00.5Z","seq":1}`},
+	}
+	for _, tt := range tests {
+		got, err := tt.format.RecordTime([]byte(tt.line))
+		if err != nil || !got.Equal(want) {
+			t.Errorf("%v.RecordTime() = %v, %v, want %v", tt.format, got, err, want)
+		}
+	}
+	if _, err := FormatNDJSON.RecordTime([]byte(`{"seq":1}`)); err == nil {
+		t.Error("RecordTime() without time error = nil")
+	}
+}
@@ -0,0 +1,127 @@
+// Package record writes monitor snapshots to local NDJSON and CSV logs
+package record
+
+import (
+	"strconv"
+	"strings"
+
+	"go-pira/pkg/pira"
+)
+
+// column is a CSV column and its value in a snapshot, empty when the
+// snapshot does not carry it
+type column struct {
+	name  string
+	value func(s *pira.Snapshot) string
+}
+
+// fmMetrics and basicMetrics fix the order of the metric columns
+var (
+	fmMetrics = []string{
+		pira.MetricFrequency,
+		pira.MetricDeviation,
+		pira.MetricDeviationMax,
+		pira.MetricDeviationAverage,
+		pira.MetricDeviationMinHold,
+		pira.MetricDeviationMaxHold,
+		pira.MetricPilotDeviation,
+		pira.MetricRDSDeviation,
+		pira.MetricRDSPhaseDifference,
+		pira.MetricModulationPower,
+		pira.MetricSignalQuality,
+		pira.MetricNoiseLevel,
+	}
+	basicMetrics = []string{
+		pira.MetricFrequency,
+		pira.MetricPilotDeviation,
+		pira.MetricRDSDeviation,
+		pira.MetricRDSPhaseDifference,
+		pira.MetricModulationPower,
+		pira.MetricSignalQuality,
+	}
+)
+
+// columns is the CSV schema. Columns are only ever appended so that
+// logs of different versions line up.
+var columns = buildColumns()
+
+func buildColumns() []column {
+	cols := []column{
+		{"time", func(s *pira.Snapshot) string { return s.Time.UTC().Format("2006-01-02T15:04:05.000Z07:00") }},
+		{"seq", func(s *pira.Snapshot) string { return strconv.FormatUint(s.Seq, 10) }},
+		{"kind", func(s *pira.Snapshot) string { return s.Kind.String() }},
+		{"error", func(s *pira.Snapshot) string {
+			if s.Err == nil {
+				return ""
+			}
+			return s.Err.Error()
+		}},
+	}
+	for _, name := range fmMetrics {
+		cols = append(cols, column{"fm." + name, func(s *pira.Snapshot) string {
+			if s.FMInfo == nil {
+				return ""
+			}
+			return formatFloat(s.FMInfo.Metrics()[name])
+		}})
+	}
+	rds := func(f func(r *pira.RDSInfo) string) func(s *pira.Snapshot) string {
+		return func(s *pira.Snapshot) string {
+			// fast snapshots carry the RDS of the last slow one
+			if s.FMInfo == nil || s.Kind != pira.SnapshotSlow {
+				return ""
+			}
+			return f(&s.FMInfo.RDS)
+		}
+	}
+	cols = append(cols,
+		column{"rds.pi", rds(func(r *pira.RDSInfo) string { return r.PI.String() })},
+		column{"rds.ps", rds(func(r *pira.RDSInfo) string { return r.PS })},
+		column{"rds.rt", rds(func(r *pira.RDSInfo) string { return strings.TrimRight(r.RT, "\r\x00 ") })},
+		column{"rds.pty", rds(func(r *pira.RDSInfo) string { return strconv.Itoa(int(r.PTY.Code)) })},
+		column{"rds.tp", rds(func(r *pira.RDSInfo) string { return strconv.FormatBool(r.Status.TP) })},
+		column{"rds.ta", rds(func(r *pira.RDSInfo) string { return strconv.FormatBool(r.Status.TA) })},
+		column{"rds.ms", rds(func(r *pira.RDSInfo) string { return strconv.FormatBool(r.Status.MS) })},
+	)
+	for _, name := range basicMetrics {
+		cols = append(cols, column{"basic." + name, func(s *pira.Snapshot) string {
+			if s.BasicData == nil {
+				return ""
+			}
+			v, ok := s.BasicData.Metrics()[name]
+			if !ok {
+				return ""
+			}
+			return formatFloat(v)
+		}})
+	}
+	cols = append(cols, column{"basic.error", func(s *pira.Snapshot) string {
+		if s.BasicErr == nil {
+			return ""
+		}
+		return s.BasicErr.Error()
+	}})
+	return cols
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// Columns returns the names of the CSV columns
+func Columns() []string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.name
+	}
+	return names
+}
+
+// Row returns the CSV values of s in the order of Columns
+func Row(s pira.Snapshot) []string {
+	row := make([]string, len(columns))
+	for i, c := range columns {
+		row[i] = c.value(&s)
+	}
+	return row
+}
@@ -0,0 +1,118 @@
+package record
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"go-pira/pkg/pira"
+)
+
+// Format is the encoding of a log
+type Format int
+
+const (
+	FormatNDJSON Format = iota
+	FormatCSV
+)
+
+// String implements the fmt.Stringer interface for Format
+func (f Format) String() string {
+	switch f {
+	case FormatNDJSON:
+		return "ndjson"
+	case FormatCSV:
+		return "csv"
+	}
+	return fmt.Sprintf("Format(%d)", int(f))
+}
+
+// ParseFormat parses "ndjson" (or "json") and "csv"
+func ParseFormat(s string) (Format, error) {
+	switch s {
+	case "ndjson", "json":
+		return FormatNDJSON, nil
+	case "csv":
+		return FormatCSV, nil
+	}
+	return 0, fmt.Errorf("unknown log format %q", s)
+}
+
+// Ext returns the file name extension of the format
+func (f Format) Ext() string {
+	if f == FormatCSV {
+		return ".csv"
+	}
+	return ".ndjson"
+}
+
+// Header returns the bytes a log file starts with, the column names for
+// CSV and nothing for NDJSON
+func (f Format) Header() []byte {
+	if f != FormatCSV {
+		return nil
+	}
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(Columns())
+	w.Flush()
+	return buf.Bytes()
+}
+
+// RecordTime returns the snapshot time of a log line, the first column
+// of CSV and the time field of NDJSON
+func (f Format) RecordTime(line []byte) (time.Time, error) {
+	if f == FormatCSV {
+		first, _, _ := bytes.Cut(line, []byte(","))
+		return time.Parse(time.RFC3339, string(first))
+	}
+	var r struct {
+		Time time.Time `json:"time"`
+	}
+	if err := json.Unmarshal(line, &r); err != nil {
+		return time.Time{}, err
+	}
+	if r.Time.IsZero() {
+		return r.Time, fmt.Errorf("record has no time")
+	}
+	return r.Time, nil
+}
+
+// Writer encodes snapshots as lines of a log. Each snapshot is written
+// with a single Write call so a rotating file never splits a line.
+type Writer struct {
+	w      io.Writer
+	format Format
+	buf    bytes.Buffer
+	csv    *csv.Writer
+}
+
+// NewWriter returns a writer of format to w. The CSV header is not
+// written, see Format.Header.
+func NewWriter(w io.Writer, format Format) *Writer {
+	lw := &Writer{w: w, format: format}
+	lw.csv = csv.NewWriter(&lw.buf)
+	return lw
+}
+
+// Write writes s as one line
+func (w *Writer) Write(s pira.Snapshot) error {
+	w.buf.Reset()
+	switch w.format {
+	case FormatCSV:
+		w.csv.Write(Row(s))
+		w.csv.Flush()
+		if err := w.csv.Error(); err != nil {
+			return err
+		}
+	default:
+		if err := json.NewEncoder(&w.buf).Encode(s); err != nil {
+			return err
+		}
+	}
+	_, err := w.w.Write(w.buf.Bytes())
+	return err
+}
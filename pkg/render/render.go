@@ -0,0 +1,241 @@
+// Package render writes command results as a table, JSON, NDJSON, YAML,
+// CSV or through a Go template. Values are rendered from their JSON
+// encoding, except for templates which see the Go value.
+package render
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Formats are the names accepted by New, any format containing "{{" is
+// a Go template
+var Formats = []string{"table", "json", "ndjson", "yaml", "csv"}
+
+// Renderer writes values in one format. Renderers keep state between
+// values: CSV writes the header once and YAML separates documents.
+type Renderer interface {
+	Render(v any) error
+}
+
+// New returns the renderer of format writing to w
+func New(w io.Writer, format string) (Renderer, error) {
+	switch format {
+	case "table":
+		return &tableRenderer{w: w}, nil
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return jsonRenderer{encoder}, nil
+	case "ndjson":
+		return jsonRenderer{json.NewEncoder(w)}, nil
+	case "yaml":
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		return yamlRenderer{encoder}, nil
+	case "csv":
+		return &csvRenderer{w: csv.NewWriter(w)}, nil
+	}
+	if strings.Contains(format, "{{") {
+		t, err := template.New("format").Funcs(funcs).Parse(format)
+		if err != nil {
+			return nil, fmt.Errorf("invalid format template: %w", err)
+		}
+		return &templateRenderer{w: w, t: t}, nil
+	}
+	return nil, fmt.Errorf("unknown format %q, want one of %s or a Go template", format, strings.Join(Formats, ", "))
+}
+
+type jsonRenderer struct {
+	encoder *json.Encoder
+}
+
+func (r jsonRenderer) Render(v any) error {
+	return r.encoder.Encode(v)
+}
+
+type yamlRenderer struct {
+	encoder *yaml.Encoder
+}
+
+func (r yamlRenderer) Render(v any) error {
+	t, err := tree(v)
+	if err != nil {
+		return err
+	}
+	return r.encoder.Encode(yamlNode(t))
+}
+
+// yamlNode converts a JSON tree, keeping the key order and the JSON types
+func yamlNode(v any) *yaml.Node {
+	switch v := v.(type) {
+	case object:
+		n := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, m := range v {
+			n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: m.Key}, yamlNode(m.Value))
+		}
+		return n
+	case []any:
+		n := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, e := range v {
+			n.Content = append(n.Content, yamlNode(e))
+		}
+		return n
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(v.String(), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: v.String()}
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: scalar(v)}
+	case nil:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: scalar(v)}
+}
+
+// tableRenderer prints objects as field and value rows and lists of
+// objects as a table with a column per field
+type tableRenderer struct {
+	w io.Writer
+}
+
+func (r *tableRenderer) Render(v any) error {
+	t, err := tree(v)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(r.w, 0, 4, 2, ' ', 0)
+	switch t := t.(type) {
+	case object:
+		for _, leaf := range flatten("", t, true, nil) {
+			fmt.Fprintf(tw, "%s\t%s\n", leaf.Key, scalar(leaf.Value))
+		}
+	case []any:
+		if scalars(t) {
+			for _, e := range t {
+				fmt.Fprintln(tw, scalar(e))
+			}
+			break
+		}
+		var header []string
+		rows := make([]object, len(t))
+		for i, e := range t {
+			rows[i] = flatten("", e, true, nil)
+			header = union(header, rows[i])
+		}
+		fmt.Fprintln(tw, strings.ToUpper(strings.Join(header, "\t")))
+		for _, row := range rows {
+			fmt.Fprintln(tw, strings.Join(values(header, row), "\t"))
+		}
+	default:
+		fmt.Fprintln(tw, scalar(t))
+	}
+	return tw.Flush()
+}
+
+// union appends the keys of row missing from header
+func union(header []string, row object) []string {
+	for _, leaf := range row {
+		if !slices.Contains(header, leaf.Key) {
+			header = append(header, leaf.Key)
+		}
+	}
+	return header
+}
+
+// values returns the values of row in the order of header, missing
+// values are empty
+func values(header []string, row object) []string {
+	out := make([]string, len(header))
+	for i, key := range header {
+		for _, leaf := range row {
+			if leaf.Key == key {
+				out[i] = scalar(leaf.Value)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// csvRenderer writes a row per value, or per element of a list. The
+// columns are fixed by the first value, later fields not in the header
+// are dropped so that the rows line up.
+type csvRenderer struct {
+	w      *csv.Writer
+	header []string
+}
+
+func (r *csvRenderer) Render(v any) error {
+	t, err := tree(v)
+	if err != nil {
+		return err
+	}
+	list, ok := t.([]any)
+	if !ok {
+		list = []any{t}
+	}
+	rows := make([]object, len(list))
+	for i, e := range list {
+		rows[i] = flatten("", e, false, nil)
+		if rows[i] == nil || rows[i][0].Key == "" {
+			rows[i] = object{{"value", e}}
+		}
+	}
+	if r.header == nil {
+		for _, row := range rows {
+			r.header = union(r.header, row)
+		}
+		if err := r.w.Write(r.header); err != nil {
+			return err
+		}
+	}
+	for _, row := range rows {
+		if err := r.w.Write(values(r.header, row)); err != nil {
+			return err
+		}
+	}
+	r.w.Flush()
+	return r.w.Error()
+}
+
+// funcs are available in format templates
+var funcs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"trim":  strings.TrimSpace,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// templateRenderer executes a Go template per value, ending the output
+// with a newline
+type templateRenderer struct {
+	w io.Writer
+	t *template.Template
+}
+
+func (r *templateRenderer) Render(v any) error {
+	var b strings.Builder
+	if err := r.t.Execute(&b, v); err != nil {
+		return err
+	}
+	out := b.String()
+	if !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+	_, err := io.WriteString(r.w, out)
+	return err
+}
@@ -0,0 +1,108 @@
+package render
+
+import (
+	"strings"
+	"testing"
+)
+
+type rds struct {
+	PI     uint16 `json:"pi"`
+	PS     string `json:"ps"`
+	TA     bool   `json:"ta"`
+	Groups []int  `json:"groups"`
+}
+
+type info struct {
+	Frequency float64
+	RDS       rds
+	Linked    []rds `json:"linked,omitempty"`
+}
+
+var value = info{Frequency: 98.5, RDS: rds{PI: 0xD3C2, PS: "RADIO 1 ", Groups: []int{10, 0}}}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		format string
+		values []any
+		want   string
+	}{
+		{
+			format: "json",
+			values: []any{rds{PS: "A"}},
+			want:   "{\n  \"pi\": 0,\n  \"ps\": \"A\",\n  \"ta\": false,\n  \"groups\": null\n}\n",
+		},
+		{
+			format: "ndjson",
+			values: []any{rds{PS: "A"}, rds{PS: "B"}},
+			want:   `{"pi":0,"ps":"A","ta":false,"groups":null}` + "\n" + `{"pi":0,"ps":"B","ta":false,"groups":null}` + "\n",
+		},
+		{
+			format: "table",
+			values: []any{value},
+			want: "Frequency   98.5\n" +
+				"RDS.pi      54210\n" +
+				"RDS.ps      RADIO 1 \n" +
+				"RDS.ta      false\n" +
+				"RDS.groups  10 0\n",
+		},
+		{
+			format: "table",
+			values: []any{[]rds{{PI: 1, PS: "A"}, {PI: 2, PS: "B", TA: true}}},
+			want: "PI  PS  TA     GROUPS\n" +
+				"1   A   false  \n" +
+				"2   B   true   \n",
+		},
+		{
+			format: "csv",
+			values: []any{value, info{Frequency: 100, Linked: []rds{{}}}},
+			want: "Frequency,RDS.pi,RDS.ps,RDS.ta,RDS.groups.0,RDS.groups.1\n" +
+				"98.5,54210,RADIO 1 ,false,10,0\n" +
+				"100,0,,false,,\n",
+		},
+		{
+			format: "csv",
+			values: []any{[]string{"a", "b"}},
+			want:   "value\na\nb\n",
+		},
+		{
+			format: "yaml",
+			values: []any{rds{PS: "true", Groups: []int{1}}, 5},
+			want:   "pi: 0\nps: \"true\"\nta: false\ngroups:\n  - 1\n---\n5\n",
+		},
+		{
+			format: "{{.RDS.PS | trim}} on {{.Frequency}}",
+			values: []any{value},
+			want:   "RADIO 1 on 98.5\n",
+		},
+		{
+			format: "{{json .RDS.Groups}}",
+			values: []any{value},
+			want:   "[10,0]\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var b strings.Builder
+			r, err := New(&b, tt.format)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			for _, v := range tt.values {
+				if err := r.Render(v); err != nil {
+					t.Fatalf("Render() error = %v", err)
+				}
+			}
+			if got := b.String(); got != tt.want {
+				t.Errorf("Render() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	for _, format := range []string{"xml", "{{.Missing"} {
+		if _, err := New(&strings.Builder{}, format); err == nil {
+			t.Errorf("New(%q) error = nil", format)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// member is a key and value of an object, objects keep the order of the
+// JSON encoding so rendered fields follow the struct declaration
+type member struct {
+	Key   string
+	Value any
+}
+
+// object is an ordered JSON object, values are object, []any, string,
+// json.Number, bool or nil
+type object []member
+
+// tree returns v as an ordered JSON tree, using the JSON marshalers and
+// tags of v
+func tree(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	return decode(d)
+}
+
+func decode(d *json.Decoder) (any, error) {
+	token, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case json.Delim('{'):
+		obj := object{}
+		for d.More() {
+			key, err := d.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decode(d)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, member{key.(string), value})
+		}
+		_, err := d.Token()
+		return obj, err
+	case json.Delim('['):
+		list := []any{}
+		for d.More() {
+			value, err := decode(d)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		_, err := d.Token()
+		return list, err
+	}
+	return token, nil
+}
+
+// flatten returns the leaves of v keyed by dotted paths. Lists of scalars
+// are joined by spaces when join is set, otherwise every element is a
+// leaf keyed by its index.
+func flatten(prefix string, v any, join bool, leaves object) object {
+	key := func(k string) string {
+		if prefix == "" {
+			return k
+		}
+		return prefix + "." + k
+	}
+	switch v := v.(type) {
+	case object:
+		for _, m := range v {
+			leaves = flatten(key(m.Key), m.Value, join, leaves)
+		}
+		return leaves
+	case []any:
+		if join && scalars(v) {
+			values := make([]string, len(v))
+			for i, e := range v {
+				values[i] = scalar(e)
+			}
+			return append(leaves, member{prefix, strings.Join(values, " ")})
+		}
+		for i, e := range v {
+			leaves = flatten(key(strconv.Itoa(i)), e, join, leaves)
+		}
+		return leaves
+	}
+	return append(leaves, member{prefix, v})
+}
+
+func scalars(list []any) bool {
+	for _, e := range list {
+		switch e.(type) {
+		case object, []any:
+			return false
+		}
+	}
+	return true
+}
+
+// scalar formats a leaf value, null is empty
+func scalar(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,237 @@
+package server
+
+import (
+	"encoding"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// route is an API endpoint, the OpenAPI description is generated from
+// the route table
+type route struct {
+	Method   string
+	Path     string
+	Summary  string
+	Params   []param
+	Request  any
+	Response any
+	handle   func(r *http.Request) (any, error)
+	// stream replaces handle for server-sent event routes
+	stream http.Handler
+	// control routes change the analyzer state and require the control token
+	control bool
+}
+
+type param struct {
+	Name        string
+	In          string
+	Description string
+	Optional    bool
+}
+
+// OpenAPI returns the OpenAPI 3.0 description of the API
+func (s *Server) OpenAPI() map[string]any {
+	errorResponse := map[string]any{
+		"description": "error",
+		"content":     jsonContent(schemaOf(reflect.TypeFor[Error]())),
+	}
+	paths := make(map[string]any)
+	for _, r := range s.routes {
+		op := map[string]any{
+			"summary":     r.Summary,
+			"operationId": operationID(r),
+		}
+		if len(r.Params) > 0 {
+			params := make([]any, len(r.Params))
+			for i, p := range r.Params {
+				params[i] = map[string]any{
+					"name":        p.Name,
+					"in":          p.In,
+					"description": p.Description,
+					"required":    !p.Optional,
+					"schema":      map[string]any{"type": "string"},
+				}
+			}
+			op["parameters"] = params
+		}
+		if r.Request != nil {
+			op["requestBody"] = map[string]any{
+				"required": true,
+				"content":  jsonContent(schemaOf(reflect.TypeOf(r.Request))),
+			}
+		}
+		responses := map[string]any{"default": errorResponse}
+		switch {
+		case r.stream != nil:
+			responses["200"] = map[string]any{
+				"description": "server-sent events",
+				"content":     map[string]any{"text/event-stream": map[string]any{"schema": map[string]any{"type": "string"}}},
+			}
+		case r.Response != nil:
+			responses["200"] = map[string]any{
+				"description": "success",
+				"content":     jsonContent(schemaOf(reflect.TypeOf(r.Response))),
+			}
+		default:
+			responses["204"] = map[string]any{"description": "success"}
+		}
+		op["responses"] = responses
+		if r.control {
+			op["security"] = []any{map[string]any{"bearer": []any{}}}
+		}
+
+		item, _ := paths[r.Path].(map[string]any)
+		if item == nil {
+			item = make(map[string]any)
+			paths[r.Path] = item
+		}
+		item[strings.ToLower(r.Method)] = op
+	}
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info":    map[string]any{"title": "gpira", "version": "1"},
+		"paths":   paths,
+		"components": map[string]any{
+			"securitySchemes": map[string]any{
+				"bearer": map[string]any{"type": "http", "scheme": "bearer"},
+			},
+		},
+	}
+}
+
+func operationID(r route) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(r.Method))
+	for _, part := range strings.Split(r.Path, "/")[2:] {
+		part = strings.Trim(part, "{}")
+		if part != "" {
+			b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	return b.String()
+}
+
+func jsonContent(schema map[string]any) map[string]any {
+	return map[string]any{"application/json": map[string]any{"schema": schema}}
+}
+
+var (
+	jsonMarshaler = reflect.TypeFor[json.Marshaler]()
+	textMarshaler = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// schemaOf derives a JSON schema from a Go type as encoding/json would
+// marshal it. Types with their own marshaler are described by the JSON
+// of their zero value.
+func schemaOf(t reflect.Type) map[string]any {
+	switch t {
+	case reflect.TypeFor[time.Time]():
+		return map[string]any{"type": "string", "format": "date-time"}
+	case reflect.TypeFor[time.Duration]():
+		return map[string]any{"type": "integer", "description": "nanoseconds"}
+	}
+	if t.Implements(jsonMarshaler) {
+		return marshalerSchema(t)
+	}
+	if t.Implements(textMarshaler) {
+		return map[string]any{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Pointer:
+		return schemaOf(t.Elem())
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": schemaOf(t.Elem())}
+	case reflect.Array:
+		return map[string]any{"type": "array", "items": schemaOf(t.Elem()), "minItems": t.Len(), "maxItems": t.Len()}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaOf(t.Elem())}
+	case reflect.Struct:
+		properties := make(map[string]any)
+		addProperties(properties, t)
+		return map[string]any{"type": "object", "properties": properties}
+	}
+	return map[string]any{}
+}
+
+func addProperties(properties map[string]any, t reflect.Type) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			addProperties(properties, f.Type)
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		properties[name] = schemaOf(f.Type)
+	}
+}
+
+// marshalerSchema describes a type with a custom marshaler
+func marshalerSchema(t reflect.Type) map[string]any {
+	data, err := json.Marshal(reflect.Zero(t).Interface())
+	if err != nil {
+		return map[string]any{}
+	}
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return map[string]any{}
+	}
+	if v == nil {
+		// nullable wrappers hold their value in a Value field
+		if f, ok := t.FieldByName("Value"); ok && t.Kind() == reflect.Struct {
+			schema := schemaOf(f.Type)
+			schema["nullable"] = true
+			return schema
+		}
+		return map[string]any{"nullable": true}
+	}
+	return valueSchema(v)
+}
+
+// valueSchema describes a decoded JSON value
+func valueSchema(v any) map[string]any {
+	switch v := v.(type) {
+	case bool:
+		return map[string]any{"type": "boolean"}
+	case float64:
+		return map[string]any{"type": "number"}
+	case string:
+		return map[string]any{"type": "string"}
+	case []any:
+		if len(v) > 0 {
+			return map[string]any{"type": "array", "items": valueSchema(v[0])}
+		}
+		return map[string]any{"type": "array"}
+	case map[string]any:
+		properties := make(map[string]any, len(v))
+		for name, value := range v {
+			properties[name] = valueSchema(value)
+		}
+		return map[string]any{"type": "object", "properties": properties}
+	}
+	return map[string]any{}
+}
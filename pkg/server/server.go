@@ -0,0 +1,277 @@
+// Package server exposes the analyzer over an HTTP JSON API
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"go-pira/pkg/pira"
+)
+
+// Device is the part of the Pira client used by the server
+type Device interface {
+	GetFMInfo(fmi *pira.FMInfo) error
+	GetBasicData() (*pira.BasicData, error)
+	GetHistogram() ([]uint16, error)
+	ReadMemory(addr, n int) ([]byte, error)
+	SetFrequency(frequency uint32) error
+	ResetHold() error
+}
+
+// errBadRequest marks errors caused by the request
+var errBadRequest = errors.New("bad request")
+
+// errNotFound marks requests for unknown resources
+var errNotFound = errors.New("not found")
+
+// Error is the JSON body of failed requests
+type Error struct {
+	Error string `json:"error"`
+}
+
+// Memory is a raw memory range
+type Memory struct {
+	Address int    `json:"address"`
+	Length  int    `json:"length"`
+	Hex     string `json:"hex"`
+}
+
+// RDSField is a single RDSInfo field, named by its JSON tag
+type RDSField struct {
+	Field string `json:"field"`
+	Value any    `json:"value"`
+}
+
+// TuneRequest is the body of POST /api/tune
+type TuneRequest struct {
+	Frequency uint32 `json:"frequency"`
+}
+
+// Server serves the API for a device
+type Server struct {
+	// ControlToken is the bearer token required by the routes that change
+	// the analyzer state, they are disabled while it is empty
+	ControlToken string
+
+	device Device
+	routes []route
+	mux    *http.ServeMux
+}
+
+// New returns a server for device
+func New(device Device) *Server {
+	s := &Server{device: device, mux: http.NewServeMux()}
+	s.routes = []route{
+		{
+			Method: "GET", Path: "/api/fminfo", Summary: "Read all FM and RDS measurements",
+			Response: pira.FMInfo{}, handle: s.getFMInfo,
+		},
+		{
+			Method: "GET", Path: "/api/basic", Summary: "Read the basic data of the ?B command",
+			Response: pira.BasicData{}, handle: s.getBasicData,
+		},
+		{
+			Method: "GET", Path: "/api/rds", Summary: "Read the RDS information",
+			Response: pira.RDSInfo{}, handle: s.getRDS,
+		},
+		{
+			Method: "GET", Path: "/api/rds/{field}", Summary: "Read a single RDS field by its JSON name",
+			Params:   []param{{Name: "field", In: "path", Description: "RDS field, e.g. ps or rt"}},
+			Response: RDSField{}, handle: s.getRDSField,
+		},
+		{
+			Method: "GET", Path: "/api/histogram", Summary: "Read the deviation histogram",
+			Response: []uint16{}, handle: s.getHistogram,
+		},
+		{
+			Method: "GET", Path: "/api/memory", Summary: "Read a raw memory range",
+			Params: []param{
+				{Name: "addr", In: "query", Description: "start address, decimal or 0x hex"},
+				{Name: "len", In: "query", Description: "number of bytes"},
+			},
+			Response: Memory{}, handle: s.getMemory,
+		},
+		{
+			Method: "POST", Path: "/api/tune", Summary: "Tune the analyzer, the frequency is in kHz",
+			Request: TuneRequest{}, handle: s.postTune, control: true,
+		},
+		{
+			Method: "POST", Path: "/api/hold/reset", Summary: "Reset the hold deviations",
+			handle: s.postResetHold, control: true,
+		},
+	}
+	for _, r := range s.routes {
+		h := s.wrap(r.handle)
+		if r.control {
+			h = s.authorize(h)
+		}
+		s.mux.Handle(r.Method+" "+r.Path, h)
+	}
+	s.mux.HandleFunc("GET /api/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, s.OpenAPI())
+	})
+	return s
+}
+
+// HandleStream serves the server-sent events of hub at /api/stream
+func (s *Server) HandleStream(hub *Hub) {
+	r := route{
+		Method: "GET", Path: "/api/stream", Summary: "Stream snapshots and change events",
+		Params: []param{
+			{Name: "fields", In: "query", Description: "comma separated dotted JSON paths of the snapshot fields to send", Optional: true},
+			{Name: "interval", In: "query", Description: "minimum duration between snapshots, e.g. 1s", Optional: true},
+			{Name: "events", In: "query", Description: "false to skip change events", Optional: true},
+		},
+		stream: hub,
+	}
+	s.routes = append(s.routes, r)
+	s.mux.Handle(r.Method+" "+r.Path, hub)
+}
+
+// Handle adds a handler next to the API routes
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// ServeHTTP implements the http.Handler interface for Server
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// wrap writes the value returned by a handler as JSON, nil is 204
+func (s *Server) wrap(handle func(r *http.Request) (any, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		v, err := handle(r)
+		switch {
+		case errors.Is(err, errBadRequest):
+			writeJSON(w, http.StatusBadRequest, Error{err.Error()})
+		case errors.Is(err, errNotFound):
+			writeJSON(w, http.StatusNotFound, Error{err.Error()})
+		case err != nil:
+			writeJSON(w, http.StatusBadGateway, Error{err.Error()})
+		case v == nil:
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			writeJSON(w, http.StatusOK, v)
+		}
+	})
+}
+
+// authorize lets requests carrying the control token through
+func (s *Server) authorize(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.ControlToken == "" {
+			writeJSON(w, http.StatusForbidden, Error{"control is disabled"})
+			return
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.ControlToken)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeJSON(w, http.StatusUnauthorized, Error{"invalid control token"})
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func (s *Server) getFMInfo(r *http.Request) (any, error) {
+	var fmi pira.FMInfo
+	if err := s.device.GetFMInfo(&fmi); err != nil {
+		return nil, err
+	}
+	return &fmi, nil
+}
+
+func (s *Server) getBasicData(r *http.Request) (any, error) {
+	return s.device.GetBasicData()
+}
+
+func (s *Server) getRDS(r *http.Request) (any, error) {
+	var fmi pira.FMInfo
+	if err := s.device.GetFMInfo(&fmi); err != nil {
+		return nil, err
+	}
+	return &fmi.RDS, nil
+}
+
+func (s *Server) getRDSField(r *http.Request) (any, error) {
+	name := r.PathValue("field")
+	index, ok := rdsFields[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown rds field %s", errNotFound, name)
+	}
+	var fmi pira.FMInfo
+	if err := s.device.GetFMInfo(&fmi); err != nil {
+		return nil, err
+	}
+	return RDSField{Field: name, Value: reflect.ValueOf(fmi.RDS).Field(index).Interface()}, nil
+}
+
+// rdsFields maps the JSON names of RDSInfo fields to their index
+var rdsFields = func() map[string]int {
+	fields := make(map[string]int)
+	t := reflect.TypeFor[pira.RDSInfo]()
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[name] = i
+		}
+	}
+	return fields
+}()
+
+func (s *Server) getHistogram(r *http.Request) (any, error) {
+	return s.device.GetHistogram()
+}
+
+func (s *Server) getMemory(r *http.Request) (any, error) {
+	addr, err := strconv.ParseInt(r.URL.Query().Get("addr"), 0, 16)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid addr: %w", errBadRequest, err)
+	}
+	n, err := strconv.ParseInt(r.URL.Query().Get("len"), 0, 16)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid len: %w", errBadRequest, err)
+	}
+	if addr < 0 || n <= 0 || addr+n > 0x1000 {
+		return nil, fmt.Errorf("%w: memory range out of bounds", errBadRequest)
+	}
+	data, err := s.device.ReadMemory(int(addr), int(n))
+	if err != nil {
+		return nil, err
+	}
+	return Memory{Address: int(addr), Length: len(data), Hex: strings.ToUpper(hex.EncodeToString(data))}, nil
+}
+
+func (s *Server) postTune(r *http.Request) (any, error) {
+	var req TuneRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		return nil, fmt.Errorf("%w: %w", errBadRequest, err)
+	}
+	if req.Frequency == 0 {
+		return nil, fmt.Errorf("%w: missing frequency", errBadRequest)
+	}
+	if req.Frequency < pira.MinFrequency || req.Frequency > pira.MaxFrequency {
+		return nil, fmt.Errorf("%w: %w", errBadRequest, pira.ErrFrequencyRange)
+	}
+	return nil, s.device.SetFrequency(req.Frequency)
+}
+
+func (s *Server) postResetHold(r *http.Request) (any, error) {
+	return nil, s.device.ResetHold()
+}
@@ -0,0 +1,165 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-pira/pkg/pira"
+)
+
+type fakeDevice struct {
+	frequency uint32
+	reset     bool
+	err       error
+}
+
+func (d *fakeDevice) GetFMInfo(fmi *pira.FMInfo) error {
+	fmi.Frequency = d.frequency
+	fmi.DeviationMax = 75000
+	fmi.RDS.PI = 0x5201
+	fmi.RDS.PS = "RADIO 1 "
+	return d.err
+}
+
+func (d *fakeDevice) GetBasicData() (*pira.BasicData, error) {
+	return &pira.BasicData{Frequency: 100.5}, d.err
+}
+
+func (d *fakeDevice) GetHistogram() ([]uint16, error) {
+	return []uint16{1, 2, 3}, d.err
+}
+
+func (d *fakeDevice) ReadMemory(addr, n int) ([]byte, error) {
+	data := make([]byte, n)
+	for i := range data {
+		data[i] = byte(addr + i)
+	}
+	return data, d.err
+}
+
+func (d *fakeDevice) SetFrequency(frequency uint32) error {
+	d.frequency = frequency
+	return d.err
+}
+
+func (d *fakeDevice) ResetHold() error {
+	d.reset = true
+	return d.err
+}
+
+func TestServer(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		token      string
+		err        error
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "fminfo", method: "GET", path: "/api/fminfo", wantStatus: 200, wantBody: `"DeviationMax":75000`},
+		{name: "basic", method: "GET", path: "/api/basic", wantStatus: 200, wantBody: `"Frequency":100.5`},
+		{name: "rds", method: "GET", path: "/api/rds", wantStatus: 200, wantBody: `"pi":"5201"`},
+		{name: "rds field", method: "GET", path: "/api/rds/ps", wantStatus: 200, wantBody: `{"field":"ps","value":"RADIO 1 "}`},
+		{name: "unknown rds field", method: "GET", path: "/api/rds/foo", wantStatus: 404, wantBody: `"error"`},
+		{name: "histogram", method: "GET", path: "/api/histogram", wantStatus: 200, wantBody: `[1,2,3]`},
+		{name: "memory", method: "GET", path: "/api/memory?addr=0x1A&len=3", wantStatus: 200, wantBody: `{"address":26,"length":3,"hex":"1A1B1C"}`},
+		{name: "memory out of range", method: "GET", path: "/api/memory?addr=0xFFF&len=2", wantStatus: 400},
+		{name: "memory bad addr", method: "GET", path: "/api/memory?addr=x&len=2", wantStatus: 400},
+		{name: "tune", method: "POST", path: "/api/tune", body: `{"frequency":100500}`, token: "secret", wantStatus: 204},
+		{name: "tune bad body", method: "POST", path: "/api/tune", body: `{"freq":1}`, token: "secret", wantStatus: 400},
+		{name: "tune out of band", method: "POST", path: "/api/tune", body: `{"frequency":10050}`, token: "secret", wantStatus: 400},
+		{name: "tune without token", method: "POST", path: "/api/tune", body: `{"frequency":100500}`, wantStatus: 401},
+		{name: "tune wrong token", method: "POST", path: "/api/tune", body: `{"frequency":100500}`, token: "guess", wantStatus: 401},
+		{name: "reset hold", method: "POST", path: "/api/hold/reset", token: "secret", wantStatus: 204},
+		{name: "device error", method: "GET", path: "/api/fminfo", err: errors.New("timeout"), wantStatus: 502, wantBody: `{"error":"timeout"}`},
+		{name: "wrong method", method: "POST", path: "/api/fminfo", wantStatus: 405},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			device := &fakeDevice{err: tt.err}
+			s := New(device)
+			s.ControlToken = "secret"
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			s.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("body = %s, want %s", rec.Body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestServer_Control(t *testing.T) {
+	device := &fakeDevice{}
+	s := New(device)
+	// control is disabled without a token
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest("POST", "/api/hold/reset", nil))
+	if rec.Code != http.StatusForbidden || device.reset {
+		t.Errorf("status = %d without control token, want %d", rec.Code, http.StatusForbidden)
+	}
+
+	s.ControlToken = "secret"
+	for _, req := range []*http.Request{
+		httptest.NewRequest("POST", "/api/tune", strings.NewReader(`{"frequency":100500}`)),
+		httptest.NewRequest("POST", "/api/hold/reset", nil),
+	} {
+		req.Header.Set("Authorization", "Bearer secret")
+		s.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	if device.frequency != 100500 || !device.reset {
+		t.Errorf("device = %+v, want tuned and reset", device)
+	}
+}
+
+func TestServer_OpenAPI(t *testing.T) {
+	rec := httptest.NewRecorder()
+	New(&fakeDevice{}).ServeHTTP(rec, httptest.NewRequest("GET", "/api/openapi.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	var doc struct {
+		Paths map[string]map[string]struct {
+			OperationID string `json:"operationId"`
+			Responses   map[string]struct {
+				Content map[string]struct {
+					Schema map[string]any `json:"schema"`
+				} `json:"content"`
+			} `json:"responses"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if len(doc.Paths) != 8 {
+		t.Errorf("paths = %d, want 8", len(doc.Paths))
+	}
+	op := doc.Paths["/api/rds"]["get"]
+	if op.OperationID != "getRds" {
+		t.Errorf("operationId = %q, want getRds", op.OperationID)
+	}
+	properties := op.Responses["200"].Content["application/json"].Schema["properties"].(map[string]any)
+	for name, want := range map[string]string{"pi": "string", "ps": "string", "pty": "object", "groups": "array", "status": "object"} {
+		schema, _ := properties[name].(map[string]any)
+		if schema["type"] != want {
+			t.Errorf("rds property %s = %v, want type %s", name, schema, want)
+		}
+	}
+	basic := doc.Paths["/api/basic"]["get"].Responses["200"].Content["application/json"].Schema["properties"].(map[string]any)
+	if pilot := basic["Pilot"].(map[string]any); pilot["type"] != "number" || pilot["nullable"] != true {
+		t.Errorf("basic Pilot schema = %v, want nullable number", pilot)
+	}
+}
@@ -0,0 +1,192 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go-pira/pkg/fields"
+	"go-pira/pkg/pira"
+)
+
+// streamBuffer is the number of messages queued per client, messages to
+// a client that does not keep up are dropped
+const streamBuffer = 32
+
+// message is a server-sent event
+type message struct {
+	event string
+	data  []byte
+	// snapshot messages are subject to the client rate limit
+	snapshot bool
+	time     time.Time
+	fields   map[string]any
+}
+
+type client struct {
+	fields   []string
+	interval time.Duration
+	events   bool
+	last     time.Time
+	ch       chan message
+}
+
+// Hub broadcasts monitor snapshots and the change events derived from
+// them to server-sent event clients. The device is read by the monitor
+// feeding the hub, clients never cause extra reads.
+//
+// Clients select what they receive with query parameters:
+//
+//	fields=fm_info.DeviationMax,fm_info.RDS.ps  dotted JSON paths, case insensitive
+//	interval=1s                                 at most one snapshot per interval
+//	events=false                                no change events
+type Hub struct {
+	mu      sync.Mutex
+	clients map[*client]struct{}
+	differ  *pira.Differ
+}
+
+// NewHub returns a hub, cfg tunes the change events
+func NewHub(cfg pira.DifferConfig) *Hub {
+	return &Hub{clients: make(map[*client]struct{}), differ: pira.NewDiffer(cfg)}
+}
+
+// Run publishes snapshots until the sequence ends or ctx is done
+func (h *Hub) Run(ctx context.Context, snapshots iter.Seq[pira.Snapshot]) {
+	for s := range snapshots {
+		if ctx.Err() != nil {
+			return
+		}
+		h.Publish(s)
+	}
+}
+
+// Publish broadcasts a snapshot and its change events
+func (h *Hub) Publish(s pira.Snapshot) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return
+	}
+	var fields map[string]any
+	json.Unmarshal(data, &fields)
+	messages := []message{{event: "snapshot", data: data, snapshot: true, time: s.Time, fields: fields}}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, e := range h.differ.Snapshot(s) {
+		data, err := json.Marshal(e)
+		if err != nil {
+			continue
+		}
+		messages = append(messages, message{event: "change", data: data})
+	}
+	for c := range h.clients {
+		for _, m := range messages {
+			if m.snapshot {
+				if c.interval > 0 && m.time.Sub(c.last) < c.interval {
+					continue
+				}
+			} else if !c.events {
+				continue
+			}
+			select {
+			case c.ch <- m:
+				// a dropped snapshot does not use up the interval
+				if m.snapshot {
+					c.last = m.time
+				}
+			default:
+			}
+		}
+	}
+}
+
+// Clients returns the number of connected clients
+func (h *Hub) Clients() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.clients)
+}
+
+// ServeHTTP implements the http.Handler interface for Hub
+func (h *Hub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c, err := newClient(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, Error{err.Error()})
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSON(w, http.StatusInternalServerError, Error{"streaming unsupported"})
+		return
+	}
+
+	h.mu.Lock()
+	h.clients[c] = struct{}{}
+	h.mu.Unlock()
+	defer func() {
+		h.mu.Lock()
+		delete(h.clients, c)
+		h.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case m := <-c.ch:
+			data := m.data
+			if m.snapshot && len(c.fields) > 0 {
+				data, err = json.Marshal(c.selectFields(m.fields))
+				if err != nil {
+					continue
+				}
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", m.event, data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func newClient(r *http.Request) (*client, error) {
+	q := r.URL.Query()
+	c := &client{events: true, ch: make(chan message, streamBuffer)}
+	if fields := q.Get("fields"); fields != "" {
+		c.fields = strings.Split(fields, ",")
+	}
+	if interval := q.Get("interval"); interval != "" {
+		d, err := time.ParseDuration(interval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid interval: %w", err)
+		}
+		c.interval = d
+	}
+	if q.Get("events") == "false" {
+		c.events = false
+	}
+	return c, nil
+}
+
+// selectFields returns the sequence number, time and the selected fields
+// of a snapshot, missing fields are left out
+func (c *client) selectFields(snapshot map[string]any) map[string]any {
+	selected := map[string]any{"seq": snapshot["seq"], "time": snapshot["time"]}
+	for _, path := range c.fields {
+		if v, ok := fields.Lookup(snapshot, path); ok {
+			selected[path] = v
+		}
+	}
+	return selected
+}
@@ -0,0 +1,131 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-pira/pkg/pira"
+)
+
+// readEvents reads n server-sent events as "event data" strings
+func readEvents(t *testing.T, r *bufio.Reader, n int) []string {
+	t.Helper()
+	var events []string
+	var event string
+	for len(events) < n {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("ReadString() error = %v after %v", err, events)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			events = append(events, event+" "+strings.TrimPrefix(line, "data: "))
+		}
+	}
+	return events
+}
+
+func connect(t *testing.T, url string) *bufio.Reader {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET %s error = %v", url, err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	return bufio.NewReader(resp.Body)
+}
+
+func waitClients(t *testing.T, hub *Hub, n int) {
+	t.Helper()
+	for range 100 {
+		if hub.Clients() == n {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("Clients() = %d, want %d", hub.Clients(), n)
+}
+
+func TestHub(t *testing.T) {
+	hub := NewHub(pira.DifferConfig{Debounce: 1})
+	s := New(&fakeDevice{})
+	s.HandleStream(hub)
+	ts := httptest.NewServer(s)
+	// registered before the clients so their connections close first
+	t.Cleanup(ts.Close)
+
+	full := connect(t, ts.URL+"/api/stream")
+	selected := connect(t, ts.URL+"/api/stream?fields=fm_info.rds.ps,fm_info.DeviationMax&events=false&interval=10s")
+	waitClients(t, hub, 2)
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	fmi := pira.FMInfo{DeviationMax: 75000, RDSDeviation: 3000, RDS: pira.RDSInfo{PS: "RADIO 1 "}}
+	hub.Publish(pira.Snapshot{Seq: 1, Time: start, FMInfo: &fmi})
+	changed := fmi
+	changed.RDS.PS = "NEWS    "
+	hub.Publish(pira.Snapshot{Seq: 2, Time: start.Add(time.Second), FMInfo: &changed})
+
+	got := readEvents(t, full, 3)
+	if !strings.HasPrefix(got[0], `snapshot {"seq":1,`) || !strings.HasPrefix(got[1], `snapshot {"seq":2,`) {
+		t.Errorf("full stream snapshots = %v", got[:2])
+	}
+	if !strings.HasPrefix(got[2], `change {"type":"ps","field":"rds.ps","old":"RADIO 1 ","new":"NEWS    "`) {
+		t.Errorf("full stream change = %v", got[2])
+	}
+
+	// the second snapshot is within the interval and the change is filtered
+	hub.Publish(pira.Snapshot{Seq: 3, Time: start.Add(time.Minute), FMInfo: &changed})
+	got = readEvents(t, selected, 2)
+	want := []string{
+		`snapshot {"fm_info.DeviationMax":75000,"fm_info.rds.ps":"RADIO 1 ","seq":1,"time":"2025-01-01T00:00:00Z"}`,
+		`snapshot {"fm_info.DeviationMax":75000,"fm_info.rds.ps":"NEWS    ","seq":3,"time":"2025-01-01T00:01:00Z"}`,
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("selected stream event %d = %s, want %s", i, got[i], want[i])
+		}
+	}
+}
+
+func TestHub_BadInterval(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewHub(pira.DifferConfig{}).ServeHTTP(rec, httptest.NewRequest("GET", "/api/stream?interval=fast", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rec.Code)
+	}
+}
+
+func TestHub_DroppedSnapshot(t *testing.T) {
+	hub := NewHub(pira.DifferConfig{})
+	c := &client{interval: 10 * time.Second, ch: make(chan message, 1)}
+	hub.clients[c] = struct{}{}
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	c.ch <- message{event: "snapshot"}
+	hub.Publish(pira.Snapshot{Seq: 1, Time: start, FMInfo: &pira.FMInfo{}})
+	<-c.ch
+	// the dropped snapshot did not use up the interval
+	hub.Publish(pira.Snapshot{Seq: 2, Time: start.Add(time.Second), FMInfo: &pira.FMInfo{}})
+	select {
+	case m := <-c.ch:
+		if !strings.HasPrefix(string(m.data), `{"seq":2,`) {
+			t.Errorf("snapshot = %s, want seq 2", m.data)
+		}
+	default:
+		t.Error("no snapshot after a dropped one")
+	}
+}